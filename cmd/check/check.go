@@ -3,6 +3,8 @@ package check
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/xmapst/logx"
@@ -27,13 +29,22 @@ func New() *cobra.Command {
 			if err != nil {
 				logx.Fatalln(err)
 			}
+			if len(paths) == 0 {
+				return fmt.Errorf("required flag(s) \"path\" not set")
+			}
 			suffix := cmd.Flags().Lookup("suffix").Value.String()
 			days, err := cmd.Flags().GetInt("days")
 			if err != nil {
 				logx.Fatalln(err)
 			}
+			workers, _ := cmd.Flags().GetInt("workers")
+			timeout, _ := cmd.Flags().GetDuration("file_timeout")
 			check := checker.New(suffix)
-			res, err := check.CheckCerts(paths...)
+			check.SetWorkers(workers)
+			check.SetTimeout(timeout)
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			res, err := check.CheckCerts(ctx, paths...)
 			if err != nil {
 				logx.Fatalln(err)
 			}
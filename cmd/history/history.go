@@ -0,0 +1,170 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// event 时间线上的一个事件
+type event struct {
+	Time   time.Time
+	Kind   string
+	Detail string
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           "history <domain|path>",
+		Short:         "Show the certificates deployed at a path or for a domain over time",
+		Long:          "Show when certificates were deployed, renewed or replaced at a path or for a domain, and the gaps in between",
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := store.Open(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			// 先按路径查找, 没有记录时按域名查找
+			history, err := s.History(args[0])
+			if err != nil {
+				return err
+			}
+			if len(history) == 0 {
+				if history, err = s.DomainHistory(args[0]); err != nil {
+					return err
+				}
+			}
+			if len(history) == 0 {
+				return fmt.Errorf("no history of %s", args[0])
+			}
+			// 未指定 --gap 时使用配置文件中的 history_gap
+			gap, _ := cmd.Flags().GetDuration("gap")
+			if !cmd.Flags().Changed("gap") {
+				conf, err := config.Parse(cmd.Flags())
+				if err != nil {
+					return err
+				}
+				if conf.HistoryGap > 0 {
+					gap = conf.HistoryGap
+				}
+			}
+			var (
+				paths  []string
+				byPath = make(map[string][]*store.Observation)
+			)
+			for _, o := range history {
+				if _, ok := byPath[o.Path]; !ok {
+					paths = append(paths, o.Path)
+				}
+				byPath[o.Path] = append(byPath[o.Path], o)
+			}
+			for i, path := range paths {
+				if i > 0 {
+					fmt.Println()
+				}
+				observations := byPath[path]
+				last := observations[len(observations)-1]
+				fmt.Printf("PATH: %s  DOMAIN: %s\n", path, last.DomainName)
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintln(w, "TIME\tEVENT\tDETAILS")
+				for _, e := range timeline(observations, gap) {
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Time.Format(time.DateTime), e.Kind, e.Detail)
+				}
+				if err = w.Flush(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Duration("gap", config.DefaultHistoryGap, "Report a gap when a path was not observed for longer than this between two different certificates, defaults to history_gap of the config file; gaps of the same certificate are recorded by the daemon per history_gap")
+	return cmd
+}
+
+// timeline 根据路径上先后部署的证书生成事件: 首次部署, 续期或替换, 未检查到的间隔及之后重新出现,
+// 过期后才被替换的时段以及最近一次检查到的证书
+func timeline(history []*store.Observation, gap time.Duration) []*event {
+	var events []*event
+	for i, o := range history {
+		if i == 0 {
+			events = append(events, &event{
+				Time: o.FirstSeen,
+				Kind: "deployed",
+				Detail: fmt.Sprintf("serial %s, valid %s ~ %s, issuer %s",
+					o.Serial, o.NotBefore.Format(time.DateOnly), o.NotAfter.Format(time.DateOnly), o.Issuer),
+			})
+			continue
+		}
+		prev := history[i-1]
+		// 同一证书的两条记录之间是守护进程记录下的缺失, 如证书被临时移除或检查中断
+		if o.Fingerprint == prev.Fingerprint {
+			events = append(events, &event{
+				Time:   prev.LastSeen,
+				Kind:   "gap",
+				Detail: fmt.Sprintf("not observed for %s until %s", days(o.FirstSeen.Sub(prev.LastSeen)), o.FirstSeen.Format(time.DateTime)),
+			}, &event{
+				Time:   o.FirstSeen,
+				Kind:   "reappeared",
+				Detail: fmt.Sprintf("serial %s, expires %s", o.Serial, o.NotAfter.Format(time.DateOnly)),
+			})
+			continue
+		}
+		if d := o.FirstSeen.Sub(prev.LastSeen); d > gap {
+			events = append(events, &event{
+				Time:   prev.LastSeen,
+				Kind:   "gap",
+				Detail: fmt.Sprintf("not observed for %s until %s", days(d), o.FirstSeen.Format(time.DateTime)),
+			})
+		}
+		if prev.NotAfter.Before(o.FirstSeen) {
+			events = append(events, &event{
+				Time:   prev.NotAfter,
+				Kind:   "expired",
+				Detail: fmt.Sprintf("serial %s expired %s before it was replaced", prev.Serial, days(o.FirstSeen.Sub(prev.NotAfter))),
+			})
+		}
+		kind := "renewed"
+		if !o.NotAfter.After(prev.NotAfter) {
+			kind = "replaced"
+		}
+		events = append(events, &event{
+			Time: o.FirstSeen,
+			Kind: kind,
+			Detail: fmt.Sprintf("serial %s → %s, expiry %s → %s, issuer %s",
+				prev.Serial, o.Serial, prev.NotAfter.Format(time.DateOnly), o.NotAfter.Format(time.DateOnly), o.Issuer),
+		})
+	}
+	last := history[len(history)-1]
+	if last.NotAfter.Before(last.LastSeen) {
+		events = append(events, &event{
+			Time:   last.NotAfter,
+			Kind:   "expired",
+			Detail: fmt.Sprintf("serial %s expired and is still deployed", last.Serial),
+		})
+	}
+	events = append(events, &event{
+		Time:   last.LastSeen,
+		Kind:   "last seen",
+		Detail: fmt.Sprintf("serial %s, expires %s", last.Serial, last.NotAfter.Format(time.DateOnly)),
+	})
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	return events
+}
+
+// days 以天和小时显示时长
+func days(d time.Duration) string {
+	d = d.Round(time.Hour)
+	day := d / (24 * time.Hour)
+	return fmt.Sprintf("%dd%dh", day, (d-day*24*time.Hour)/time.Hour)
+}
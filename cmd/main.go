@@ -1,15 +1,23 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/kardianos/service"
 	"github.com/spf13/cobra"
 	"github.com/xmapst/logx"
 
 	"github.com/busybox-org/cert-checker/cmd/check"
+	"github.com/busybox-org/cert-checker/cmd/history"
+	servicecmd "github.com/busybox-org/cert-checker/cmd/service"
+	"github.com/busybox-org/cert-checker/cmd/silence"
+	"github.com/busybox-org/cert-checker/internal/config"
 	"github.com/busybox-org/cert-checker/internal/core"
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
 )
 
 func main() {
@@ -23,6 +31,11 @@ func main() {
 			UnknownFlags: true,
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			lang := cmd.Flags().Lookup("lang").Value.String()
+			if !i18n.Supported(lang) {
+				return fmt.Errorf("unsupported language: %s", lang)
+			}
+			i18n.SetLang(lang)
 			logfile := cmd.Flags().Lookup("log_file").Value.String()
 			if logfile != "" {
 				logx.SetupConsoleLogger(logfile)
@@ -39,7 +52,11 @@ func main() {
 				logx.Errorln(err)
 				return err
 			}
-			svc, err := service.New(core.New(cmd.Flags()), &service.Config{
+			program, err := core.New(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			svc, err := service.New(program, &service.Config{
 				Name:        name,
 				DisplayName: name,
 				Description: "Operating System Remote Executor Api",
@@ -56,26 +73,48 @@ func main() {
 			return nil
 		},
 	}
+	root.PersistentFlags().StringP("config", "c", "", "Path to the YAML config file, overrides flags (Optional)")
+	root.Flags().Bool("watch_config", true, "Reload the config file when it changes, SIGHUP always triggers a reload (Optional)")
 	// check flags
-	root.PersistentFlags().StringSliceP("path", "p", nil, "Directory or file paths to check (required)")
-	_ = root.MarkPersistentFlagRequired("path")
+	root.PersistentFlags().StringSliceP("path", "p", nil, "Directory or file paths to check (required unless targets are configured)")
+	root.Flags().Bool("watch", false, "Watch the target paths and recheck certificate files as soon as they change (Optional)")
 	root.PersistentFlags().String("suffix", ".crt", "File suffix to check (Optional)")
+	root.PersistentFlags().Int("workers", 0, "Number of certificate files parsed concurrently, 0 means the number of CPUs (Optional)")
+	root.PersistentFlags().Duration("file_timeout", checker.DefaultTimeout, "Timeout for checking a single certificate file (Optional)")
 	root.PersistentFlags().IntP("days", "d", 15, "Number of remaining days (Optional)")
+	// state flags
+	root.PersistentFlags().String("state_file", "cert-checker.db", "State file, relative to the executable directory (Optional)")
+	root.Flags().Bool("parse_cache", true, "Reuse parsed certificates from the state file while the file's device, inode, size and mtime are unchanged (Optional)")
+	root.Flags().Bool("verify_cache", false, "Also compare the file content hash before reusing a cached certificate (Optional)")
+	root.Flags().Duration("history_gap", config.DefaultHistoryGap, "Start a new history record when a path was not observed for longer than this (Optional)")
+	root.Flags().Duration("renotify", 24*time.Hour, "Interval to repeat an alert for an unchanged status (Optional)")
 
 	// alert flags
 	root.Flags().StringP("alert_type", "t", "dingtalk", "Type of alert")
-	root.Flags().String("alert_ak", "", "Access key for alerting (required)")
-	_ = root.MarkFlagRequired("alert_ak")
+	root.Flags().String("alert_ak", "", "Access key for alerting (required by dingtalk)")
 	root.Flags().String("alert_sk", "", "Secret key for alerting (Optional)")
+	root.Flags().String("alert_lang", "", "Language of alert messages, defaults to --lang (Optional)")
 	// log flags
 	root.Flags().StringP("log_file", "l", "", "Path to the log file (Optional)")
+	root.PersistentFlags().String("lang", i18n.Default, "Language of log messages, en or zh (Optional)")
 	// cron flags
 	root.Flags().String("cron", "0 8 * * 1-5", "Cron expression for automatic execution (Optional)")
+	root.Flags().Bool("run_on_start", false, "Run a check immediately after the daemon starts (Optional)")
+	root.Flags().Bool("validate", false, "On start, run one check and send a test alert to every channel, exit with an error if any of them fails (Optional)")
+	// metrics flags
+	root.Flags().String("metrics_listen", "", "Address to expose Prometheus metrics on, e.g. 127.0.0.1:9115 (Optional)")
+	// api flags
+	root.Flags().String("api_listen", "", "Address of the local management API, unix:///path/to.sock or a loopback address such as 127.0.0.1:9116 (Optional)")
+	// web flags
+	root.Flags().String("web_listen", "", "Address to serve the certificate dashboard on, e.g. 127.0.0.1:9117 (Optional)")
 	// self update flags
 	root.Flags().String("self_url", "https://oss.yfdou.com/tools/cert-checker", "URL for self-update (Optional)")
 
 	root.AddCommand(
 		check.New(),
+		silence.New(),
+		history.New(),
+		servicecmd.New(root.LocalNonPersistentFlags()),
 	)
 	if err := root.Execute(); err != nil {
 		logx.Fatalln(err)
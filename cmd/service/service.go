@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	ksvc "github.com/kardianos/service"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/osext"
+)
+
+// DefaultName 默认的服务名称
+const DefaultName = "cert-checker"
+
+// absFlags 值为本地路径的参数, 安装时转换为绝对路径, 因为服务的工作目录不是当前目录
+var absFlags = map[string]bool{
+	"config":   true,
+	"path":     true,
+	"log_file": true,
+}
+
+// New 服务管理子命令, daemon 为守护进程的参数, install 时会将已设置的参数写入服务配置
+func New(daemon *pflag.FlagSet) *cobra.Command {
+	root := &cobra.Command{
+		Use:           "service",
+		Short:         "Manage the system service of the daemon",
+		Long:          "Install, uninstall, start, stop, restart the system service of the daemon or show its status",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.PersistentFlags().String("name", DefaultName, "Name of the service (Optional)")
+	root.PersistentFlags().Bool("user", false, "Manage a user-level service instead of a system one (Optional)")
+	root.AddCommand(
+		newInstall(daemon),
+		newControl("uninstall", "Uninstall the service"),
+		newControl("start", "Start the service"),
+		newControl("stop", "Stop the service"),
+		newControl("restart", "Restart the service"),
+		newStatus(),
+	)
+	return root
+}
+
+func newInstall(daemon *pflag.FlagSet) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the service with the given flags",
+		Long:  "Install the service, the daemon flags given to this command are passed to the service on every start",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// 安装前先校验配置, 避免安装一个无法启动的服务
+			if _, err := config.Load(cmd.Flags()); err != nil {
+				return err
+			}
+			arguments, err := daemonArgs(cmd)
+			if err != nil {
+				return err
+			}
+			svc, err := newService(cmd, arguments)
+			if err != nil {
+				return err
+			}
+			if err = ksvc.Control(svc, "install"); err != nil {
+				return err
+			}
+			fmt.Printf("service %s installed\n", svc.String())
+			return nil
+		},
+	}
+	cmd.Flags().AddFlagSet(daemon)
+	return cmd
+}
+
+func newControl(action, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   action,
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := newService(cmd, nil)
+			if err != nil {
+				return err
+			}
+			if err = ksvc.Control(svc, action); err != nil {
+				return err
+			}
+			fmt.Printf("service %s %s\n", svc.String(), past(action))
+			return nil
+		},
+	}
+}
+
+// newStatus 仅输出服务状态, 以退出码区分: 运行中为 0, 已停止为 3, 未安装或未知为 4, 与 LSB 及 systemctl 一致
+func newStatus() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of the service",
+		Long:  "Show the status of the service, exit with 0 if it is running, 3 if it is stopped, 4 if it is not installed or unknown",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			svc, err := newService(cmd, nil)
+			if err != nil {
+				return err
+			}
+			status, err := svc.Status()
+			switch {
+			case errors.Is(err, ksvc.ErrNotInstalled):
+				fmt.Printf("service %s not installed\n", svc.String())
+				os.Exit(4)
+			case err != nil:
+				return err
+			}
+			switch status {
+			case ksvc.StatusRunning:
+				fmt.Printf("service %s running\n", svc.String())
+			case ksvc.StatusStopped:
+				fmt.Printf("service %s stopped\n", svc.String())
+				os.Exit(3)
+			default:
+				fmt.Printf("service %s status unknown\n", svc.String())
+				os.Exit(4)
+			}
+			return nil
+		},
+	}
+}
+
+// newService 按 --name/--user 创建服务, arguments 仅在安装时需要
+func newService(cmd *cobra.Command, arguments []string) (ksvc.Service, error) {
+	name, _ := cmd.Flags().GetString("name")
+	user, _ := cmd.Flags().GetBool("user")
+	executable, err := osext.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return ksvc.New(nil, &ksvc.Config{
+		Name:        name,
+		DisplayName: name,
+		Description: "CertChecker - Check SSL Certs Validity",
+		Executable:  executable,
+		Arguments:   arguments,
+		Dependencies: []string{
+			"After=network-online.target nss-lookup.target",
+			"Wants=network-online.target",
+		},
+		Option: ksvc.KeyValue{
+			"UserService":  user,
+			"Restart":      "always",
+			"ReloadSignal": "HUP",
+		},
+	})
+}
+
+// daemonArgs 将显式设置的守护进程参数还原为命令行, 服务相关的参数除外
+func daemonArgs(cmd *cobra.Command) ([]string, error) {
+	var (
+		args []string
+		err  error
+	)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if err != nil || cmd.Parent().PersistentFlags().Lookup(f.Name) != nil {
+			return
+		}
+		var values []string
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			values = sv.GetSlice()
+		} else {
+			values = []string{f.Value.String()}
+		}
+		for _, v := range values {
+			if absFlags[f.Name] && v != "" {
+				if v, err = filepath.Abs(v); err != nil {
+					return
+				}
+			}
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, v))
+		}
+	})
+	return args, err
+}
+
+func past(action string) string {
+	switch action {
+	case "stop":
+		return "stopped"
+	default:
+		return action + "ed"
+	}
+}
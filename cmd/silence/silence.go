@@ -0,0 +1,183 @@
+package silence
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/busybox-org/cert-checker/internal/glob"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+func New() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "silence",
+		Short:         "Manage silences of known-expiring certificates",
+		Long:          "Manage silences of known-expiring certificates",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(
+		newAdd(),
+		newList(),
+		newExpire(),
+	)
+	return root
+}
+
+func newAdd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a silence",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := store.Open(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			fingerprint, _ := cmd.Flags().GetString("fingerprint")
+			if fingerprint, err = normalizeFingerprint(fingerprint); err != nil {
+				return err
+			}
+			domain, _ := cmd.Flags().GetString("domain")
+			path, _ := cmd.Flags().GetString("cert_path")
+			if fingerprint == "" && domain == "" && path == "" {
+				return fmt.Errorf("one of --fingerprint, --domain or --cert_path is required")
+			}
+			for _, pattern := range []string{domain, path} {
+				if _, err = glob.Compile(pattern); err != nil {
+					return err
+				}
+			}
+			duration, _ := cmd.Flags().GetDuration("duration")
+			if duration <= 0 {
+				return fmt.Errorf("duration must be positive")
+			}
+			comment, _ := cmd.Flags().GetString("comment")
+			author, _ := cmd.Flags().GetString("author")
+			if author == "" {
+				if u, err := user.Current(); err == nil {
+					author = u.Username
+				}
+			}
+			now := time.Now()
+			silence := &store.Silence{
+				ID:          newID(),
+				Fingerprint: fingerprint,
+				Domain:      domain,
+				Path:        path,
+				Comment:     comment,
+				CreatedBy:   author,
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(duration),
+			}
+			if err = s.SaveSilence(silence); err != nil {
+				return err
+			}
+			fmt.Println(silence.ID)
+			return nil
+		},
+	}
+	cmd.Flags().String("fingerprint", "", "SHA-256 fingerprint of the certificate, hex with or without colons as printed by openssl x509 -fingerprint -sha256")
+	cmd.Flags().String("domain", "", "Domain pattern, e.g. *.example.com")
+	cmd.Flags().String("cert_path", "", "Certificate path pattern, ** matches any directories")
+	cmd.Flags().Duration("duration", 30*24*time.Hour, "How long the silence lasts")
+	cmd.Flags().String("comment", "", "Reason of the silence")
+	cmd.Flags().String("author", "", "Creator of the silence, defaults to the current user")
+	return cmd
+}
+
+func newList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List silences",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := store.Open(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			silences, err := s.Silences()
+			if err != nil {
+				return err
+			}
+			all, _ := cmd.Flags().GetBool("all")
+			now := time.Now()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ID\tFINGERPRINT\tDOMAIN\tPATH\tEXPIRES\tCREATED BY\tCOMMENT")
+			for _, v := range silences {
+				if !all && !v.Active(now) {
+					continue
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					v.ID, v.Fingerprint, v.Domain, v.Path, v.ExpiresAt.Format(time.DateTime), v.CreatedBy, v.Comment)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().Bool("all", false, "Include expired silences")
+	return cmd
+}
+
+func newExpire() *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire <id>...",
+		Short: "Expire silences immediately",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := store.Open(cmd.Flags())
+			if err != nil {
+				return err
+			}
+			silences, err := s.Silences()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			for _, id := range args {
+				var found *store.Silence
+				for _, v := range silences {
+					if v.ID == id {
+						found = v
+						break
+					}
+				}
+				if found == nil {
+					return fmt.Errorf("silence %s not found", id)
+				}
+				if !found.Active(now) {
+					continue
+				}
+				found.ExpiresAt = now
+				if err = s.SaveSilence(found); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// normalizeFingerprint 将 openssl 输出的 AB:CD:... 格式转换为检查结果中的小写十六进制, 并校验长度
+func normalizeFingerprint(fingerprint string) (string, error) {
+	if fingerprint == "" {
+		return "", nil
+	}
+	fingerprint = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+	if b, err := hex.DecodeString(fingerprint); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid fingerprint %s: expected a SHA-256 fingerprint of %d hex digits", fingerprint, sha256.Size*2)
+	}
+	return fingerprint, nil
+}
+
+func newID() string {
+	var b = make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,31 @@
+package silence
+
+import "testing"
+
+func TestNormalizeFingerprint(t *testing.T) {
+	const want = "3f2a9c0b7d1e4f5a6b8c9d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c"
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{want, want, false},
+		{"3F:2A:9C:0B:7D:1E:4F:5A:6B:8C:9D:0E:1F:2A:3B:4C:5D:6E:7F:80:91:A2:B3:C4:D5:E6:F7:08:19:2A:3B:4C", want, false},
+		{" 3F2A9C0B7D1E4F5A6B8C9D0E1F2A3B4C5D6E7F8091A2B3C4D5E6F708192A3B4C\n", want, false},
+		// SHA-1 指纹长度不符
+		{"AB:CD:EF:01:23:45:67:89:AB:CD:EF:01:23:45:67:89:AB:CD:EF:01", "", true},
+		{"sha256=" + want, "", true},
+		{"zz" + want[2:], "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeFingerprint(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("normalizeFingerprint(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeFingerprint(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ module github.com/busybox-org/cert-checker
 go 1.24.1
 
 require (
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/imroc/req/v3 v3.50.0
 	github.com/kardianos/service v1.2.2
 	github.com/miekg/dns v1.1.64
@@ -11,6 +12,8 @@ require (
 	github.com/spf13/cobra v1.9.1
 	github.com/spf13/pflag v1.0.6
 	github.com/xmapst/logx v1.0.4
+	go.etcd.io/bbolt v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -30,7 +33,6 @@ require (
 	github.com/quic-go/qpack v0.5.1 // indirect
 	github.com/quic-go/quic-go v0.50.1 // indirect
 	github.com/refraction-networking/utls v1.6.7 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	go.uber.org/mock v0.5.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	go.uber.org/zap v1.27.0 // indirect
@@ -3,17 +3,23 @@ package alerter
 import (
 	"github.com/imroc/req/v3"
 	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/i18n"
 )
 
 type IAlert interface {
 	SetUrl(url string)
 	SetAk(ak string)
 	SetSk(sk string)
-	Send(text string)
+	SetLang(lang string)
+	Send(msg *Message) error
+	// Limit 单条消息的最大字节数, 0 表示不限制
+	Limit() int
 }
 
 func New(t string) IAlert {
 	base := &sBase{
+		lang: i18n.Default,
 		http: req.NewClient().
 			EnableHTTP3().
 			EnableDumpAllAsync().
@@ -26,6 +32,42 @@ func New(t string) IAlert {
 		return &sDingTalk{
 			sBase: base,
 		}
+	case "pagerduty":
+		return &sPagerDuty{
+			sBase: base,
+		}
+	case "opsgenie":
+		return &sOpsgenie{
+			sBase: base,
+		}
+	case "alertmanager":
+		return &sAlertmanager{
+			sBase: base,
+		}
+	case "telegram":
+		return &sTelegram{
+			sBase: base,
+		}
+	case "discord":
+		return &sDiscord{
+			sBase: base,
+		}
+	case "ntfy":
+		return &sNtfy{
+			sBase: base,
+		}
+	case "gotify":
+		return &sGotify{
+			sBase: base,
+		}
+	case "syslog":
+		return &sSyslog{
+			sBase: base,
+		}
+	case "journald":
+		return &sJournald{
+			sBase: base,
+		}
 	default:
 		return base
 	}
@@ -0,0 +1,193 @@
+package alerter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// request 测试服务收到的请求
+type request struct {
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newServer 启动记录请求的测试服务, 以 status 与 reply 应答
+func newServer(t *testing.T, status int, reply string) (*httptest.Server, *request) {
+	t.Helper()
+	var got request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		got.Path = r.URL.Path
+		got.Header = r.Header.Clone()
+		if err = json.Unmarshal(data, &got.Body); err != nil {
+			t.Errorf("unmarshal body %q: %v", data, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+func newAlert(t string, url, ak, sk string) IAlert {
+	alert := New(t)
+	alert.SetUrl(url)
+	alert.SetAk(ak)
+	alert.SetSk(sk)
+	alert.SetLang("en")
+	return alert
+}
+
+func TestEscapeMarkdownV2(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com", `example\.com`},
+		{"a_b*c[d](e)~f`g", "a\\_b\\*c\\[d\\]\\(e\\)\\~f\\`g"},
+		{">#+-=|{}.!", `\>\#\+\-\=\|\{\}\.\!`},
+		{`C:\certs`, `C:\\certs`},
+		{"证书 2025-01-01", `证书 2025\-01\-01`},
+	}
+	for _, tt := range tests {
+		if got := escapeMarkdownV2(tt.in); got != tt.want {
+			t.Errorf("escapeMarkdownV2(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	srv, got := newServer(t, http.StatusOK, `{"ok":true}`)
+	alert := newAlert("telegram", srv.URL, "token", "42")
+	err := alert.Send(&Message{
+		Title:    "Cert (prod)",
+		Text:     "## Expiring\n**example.com** expires in 3 days.",
+		Severity: SeverityInfo,
+		Buttons:  []*Button{{Title: "Open", URL: "https://example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/bottoken/sendMessage" {
+		t.Errorf("path = %q", got.Path)
+	}
+	want := "*Cert \\(prod\\)*\n\nExpiring\nexample\\.com expires in 3 days\\."
+	if got.Body["text"] != want {
+		t.Errorf("text = %q, want %q", got.Body["text"], want)
+	}
+	if got.Body["parse_mode"] != "MarkdownV2" || got.Body["chat_id"] != "42" {
+		t.Errorf("body = %v", got.Body)
+	}
+	if got.Body["disable_notification"] != true {
+		t.Errorf("info message should be silent: %v", got.Body)
+	}
+	if _, ok := got.Body["reply_markup"]; !ok {
+		t.Errorf("missing inline keyboard: %v", got.Body)
+	}
+}
+
+func TestTelegramSendError(t *testing.T) {
+	srv, _ := newServer(t, http.StatusBadRequest, `{"ok":false,"error_code":400,"description":"can't parse entities"}`)
+	err := newAlert("telegram", srv.URL, "token", "42").Send(&Message{Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "can't parse entities") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDiscordSend(t *testing.T) {
+	srv, got := newServer(t, http.StatusNoContent, "")
+	err := newAlert("discord", srv.URL, "", "").Send(&Message{
+		Title:    "Cert",
+		Text:     "<font color=FF0000>expired</font>",
+		Severity: SeverityCritical,
+		AtAll:    true,
+		Buttons:  []*Button{{Title: "A", URL: "https://a"}, {Title: "B", URL: "https://b"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	embeds, ok := got.Body["embeds"].([]any)
+	if !ok || len(embeds) != 1 {
+		t.Fatalf("embeds = %v", got.Body["embeds"])
+	}
+	embed := embeds[0].(map[string]any)
+	if embed["title"] != "Cert" {
+		t.Errorf("title = %v", embed["title"])
+	}
+	if embed["description"] != "expired\n\n[A](https://a) | [B](https://b)" {
+		t.Errorf("description = %q", embed["description"])
+	}
+	if embed["color"] != float64(0xE53935) {
+		t.Errorf("color = %v", embed["color"])
+	}
+	if got.Body["content"] != "@everyone" {
+		t.Errorf("content = %v", got.Body["content"])
+	}
+}
+
+func TestNtfySend(t *testing.T) {
+	srv, got := newServer(t, http.StatusOK, `{}`)
+	err := newAlert("ntfy", srv.URL, "certs", "secret").Send(&Message{
+		Title:    "证书告警",
+		Text:     "**example.com**",
+		Severity: SeverityWarning,
+		Buttons:  []*Button{{Title: "Open", URL: "https://example.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("authorization = %q", got.Header.Get("Authorization"))
+	}
+	if got.Body["topic"] != "certs" || got.Body["title"] != "证书告警" || got.Body["markdown"] != true {
+		t.Errorf("body = %v", got.Body)
+	}
+	if got.Body["priority"] != float64(4) {
+		t.Errorf("priority = %v", got.Body["priority"])
+	}
+	actions, ok := got.Body["actions"].([]any)
+	if !ok || len(actions) != 1 || actions[0].(map[string]any)["action"] != "view" {
+		t.Errorf("actions = %v", got.Body["actions"])
+	}
+}
+
+func TestGotifySend(t *testing.T) {
+	srv, got := newServer(t, http.StatusOK, `{}`)
+	err := newAlert("gotify", srv.URL+"/", "app-token", "").Send(&Message{
+		Text:     "expiring",
+		Severity: SeverityCritical,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != "/message" {
+		t.Errorf("path = %q", got.Path)
+	}
+	if got.Header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("X-Gotify-Key = %q", got.Header.Get("X-Gotify-Key"))
+	}
+	if got.Body["message"] != "expiring" || got.Body["priority"] != float64(8) {
+		t.Errorf("body = %v", got.Body)
+	}
+	extras, _ := got.Body["extras"].(map[string]any)
+	display, _ := extras["client::display"].(map[string]any)
+	if display["contentType"] != "text/markdown" {
+		t.Errorf("extras = %v", got.Body["extras"])
+	}
+}
+
+func TestGotifySendError(t *testing.T) {
+	srv, _ := newServer(t, http.StatusUnauthorized, `{"error":"Unauthorized","errorCode":401,"errorDescription":"invalid token"}`)
+	err := newAlert("gotify", srv.URL, "bad", "").Send(&Message{Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Fatalf("err = %v", err)
+	}
+}
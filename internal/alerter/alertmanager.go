@@ -0,0 +1,118 @@
+package alerter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xmapst/logx"
+)
+
+// alertmanagerTTL 推送的告警在该时间后未刷新即自动恢复
+const alertmanagerTTL = 5 * time.Minute
+
+var invalidLabelName = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+var _ IKeepalive = (*sAlertmanager)(nil)
+
+// sAlertmanager 推送到 Alertmanager /api/v2/alerts, 同时设置 ak 与 sk 时使用 Basic 认证,
+// 仅设置 ak 时作为 Bearer Token
+type sAlertmanager struct {
+	*sBase
+}
+
+func (a *sAlertmanager) TTL() time.Duration {
+	return alertmanagerTTL
+}
+
+// Send 以 info 级别推送文本消息
+func (a *sAlertmanager) Send(msg *Message) error {
+	now := time.Now()
+	return a.post(map[string]any{
+		"labels": map[string]string{
+			"alertname": "CertCheckerMessage",
+			"severity":  SeverityInfo,
+		},
+		"annotations": map[string]string{
+			"summary":     a.title(msg),
+			"description": msg.Text,
+		},
+		"startsAt": now.Format(time.RFC3339),
+		"endsAt":   now.Add(a.TTL()).Format(time.RFC3339),
+	})
+}
+
+func (a *sAlertmanager) Trigger(e *Event) error {
+	return a.post(a.alert(e, time.Now().Add(a.TTL())))
+}
+
+func (a *sAlertmanager) Resolve(e *Event) error {
+	return a.post(a.alert(e, time.Now()))
+}
+
+// alert 标签需在多次推送间保持不变, 剩余天数以及随之升级的级别等变化的内容放在注解中,
+// 否则级别变化后会产生新的告警而原告警直到过期才恢复
+func (a *sAlertmanager) alert(e *Event, endsAt time.Time) map[string]any {
+	labels := map[string]string{
+		"alertname": "CertificateExpiring",
+		"instance":  e.Source,
+		"path":      e.Path,
+		"domain":    e.DomainName,
+		"dedup_key": e.Key,
+	}
+	if e.Owner != "" {
+		labels["owner"] = e.Owner
+	}
+	if e.Team != "" {
+		labels["team"] = e.Team
+	}
+	for k, v := range e.Labels {
+		k = invalidLabelName.ReplaceAllString(k, "_")
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	startsAt := e.StartsAt
+	if startsAt.IsZero() {
+		startsAt = time.Now()
+	}
+	return map[string]any{
+		"labels": labels,
+		"annotations": map[string]string{
+			"summary":      e.Summary,
+			"severity":     e.Severity,
+			"status":       e.Status,
+			"expired_days": fmt.Sprint(e.ExpiredDays),
+			"not_after":    e.NotAfter.Format(time.RFC3339),
+			"serial":       e.Serial,
+		},
+		"startsAt": startsAt.Format(time.RFC3339),
+		"endsAt":   endsAt.Format(time.RFC3339),
+	}
+}
+
+func (a *sAlertmanager) post(alerts ...map[string]any) error {
+	if a.url == "" {
+		return fmt.Errorf("alertmanager url is empty")
+	}
+	defer func() {
+		a.http.CloseIdleConnections()
+	}()
+	req := a.http.NewRequest().SetBody(alerts)
+	switch {
+	case a.ak != "" && a.sk != "":
+		req.SetBasicAuth(a.ak, a.sk)
+	case a.ak != "":
+		req.SetBearerAuthToken(a.ak)
+	}
+	res, err := req.Post(strings.TrimSuffix(a.url, "/") + "/api/v2/alerts")
+	if err != nil {
+		return fmt.Errorf("alertmanager send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		return fmt.Errorf("alertmanager send error: %s %s", res.GetStatus(), res.String())
+	}
+	return nil
+}
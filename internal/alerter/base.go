@@ -3,6 +3,8 @@ package alerter
 import (
 	"github.com/imroc/req/v3"
 	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/i18n"
 )
 
 var _ IAlert = (*sBase)(nil)
@@ -12,10 +14,23 @@ type sBase struct {
 	url  string
 	ak   string
 	sk   string
+	lang string
+}
+
+func (s *sBase) Send(msg *Message) error {
+	logx.Infoln(msg.Text)
+	return nil
 }
 
-func (s *sBase) Send(text string) {
-	logx.Infoln(text)
+func (s *sBase) title(msg *Message) string {
+	if msg.Title != "" {
+		return msg.Title
+	}
+	return i18n.Tl(s.lang, i18n.AlertTitle)
+}
+
+func (s *sBase) Limit() int {
+	return 0
 }
 
 func (s *sBase) SetUrl(url string) {
@@ -29,3 +44,7 @@ func (s *sBase) SetAk(ak string) {
 func (s *sBase) SetSk(sk string) {
 	s.sk = sk
 }
+
+func (s *sBase) SetLang(lang string) {
+	s.lang = i18n.Normalize(lang)
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/xmapst/logx"
@@ -12,17 +13,26 @@ import (
 
 const dingtalkRobotUrl = "https://oapi.dingtalk.com/robot/send"
 
+// 钉钉 markdown 消息内容上限为 20000 字节
+const dingtalkLimit = 20000
+
 type sDingTalk struct {
 	*sBase
 }
 
-func (d *sDingTalk) Send(text string) {
+type dingtalkResult struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (d *sDingTalk) Send(msg *Message) error {
 	if d.ak == "" {
-		logx.Errorf("dingtalk ak is empty")
-		return
+		return fmt.Errorf("dingtalk ak is empty")
 	}
 	if d.sBase.url == "" {
 		d.http.SetBaseURL(dingtalkRobotUrl)
+	} else {
+		d.http.SetBaseURL(d.sBase.url)
 	}
 
 	defer func() {
@@ -36,18 +46,83 @@ func (d *sDingTalk) Send(text string) {
 	if sign != "" {
 		req.SetQueryParam("sign", sign)
 	}
-	res, err := req.SetBody(map[string]any{
+	res, err := req.SetBody(d.body(msg)).Post("")
+	if err != nil {
+		return fmt.Errorf("dingtalk send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		return fmt.Errorf("dingtalk send error: %s", res.GetStatus())
+	}
+	// 钉钉在 HTTP 200 中通过 errcode 返回业务错误, 如签名错误或触发限流
+	var result dingtalkResult
+	if err = res.Unmarshal(&result); err != nil {
+		return fmt.Errorf("dingtalk send error: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("dingtalk send error: %d %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// body 配置了按钮时发送 ActionCard 消息, 否则发送 markdown 消息,
+// 钉钉仅在 markdown 正文中包含 @手机号 或 @用户ID 时才会高亮提醒;
+// ActionCard 不支持 @ 提醒, 配置校验时拒绝与 mention_owners 或 at_all 同时使用
+func (d *sDingTalk) body(msg *Message) map[string]any {
+	if len(msg.Buttons) > 0 {
+		card := map[string]any{
+			"title":          d.title(msg),
+			"text":           msg.Text,
+			"btnOrientation": "0",
+		}
+		if msg.Vertical {
+			card["btnOrientation"] = "1"
+		}
+		if len(msg.Buttons) == 1 {
+			card["singleTitle"] = msg.Buttons[0].Title
+			card["singleURL"] = msg.Buttons[0].URL
+		} else {
+			var btns []map[string]string
+			for _, b := range msg.Buttons {
+				btns = append(btns, map[string]string{
+					"title":     b.Title,
+					"actionURL": b.URL,
+				})
+			}
+			card["btns"] = btns
+		}
+		return map[string]any{
+			"msgtype":    "actionCard",
+			"actionCard": card,
+		}
+	}
+	text := msg.Text
+	var mentions []string
+	for _, v := range msg.AtMobiles {
+		mentions = append(mentions, "@"+v)
+	}
+	for _, v := range msg.AtUserIds {
+		mentions = append(mentions, "@"+v)
+	}
+	if len(mentions) > 0 {
+		text += "\n\n" + strings.Join(mentions, " ")
+	}
+	return map[string]any{
 		"msgtype": "markdown",
 		"markdown": map[string]any{
-			"title": "域名证书即将过期",
+			"title": d.title(msg),
 			"text":  text,
 		},
-	}).Post("")
-	if err != nil {
-		logx.Errorf("dingtalk send error: %v", err)
-		return
+		"at": map[string]any{
+			"atMobiles": msg.AtMobiles,
+			"atUserIds": msg.AtUserIds,
+			"isAtAll":   msg.AtAll,
+		},
 	}
-	logx.Infoln(res.String())
+}
+
+func (d *sDingTalk) Limit() int {
+	return dingtalkLimit
 }
 
 func (d *sDingTalk) getSign(timestamp int64) (sign string) {
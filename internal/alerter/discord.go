@@ -0,0 +1,80 @@
+package alerter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xmapst/logx"
+)
+
+var _ IAlert = (*sDiscord)(nil)
+
+// sDiscord Discord Webhook, url 为频道的 webhook 地址
+type sDiscord struct {
+	*sBase
+}
+
+type discordResult struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (d *sDiscord) Send(msg *Message) error {
+	if d.url == "" {
+		return fmt.Errorf("discord webhook url is empty")
+	}
+	description := stripTags(msg.Text)
+	if len(msg.Buttons) > 0 {
+		var links []string
+		for _, b := range msg.Buttons {
+			links = append(links, fmt.Sprintf("[%s](%s)", b.Title, b.URL))
+		}
+		description += "\n\n" + strings.Join(links, " | ")
+	}
+	body := map[string]any{
+		"username": "cert-checker",
+		"embeds": []map[string]any{
+			{
+				"title":       d.title(msg),
+				"description": description,
+				"color":       discordColor(msg.Severity),
+			},
+		},
+	}
+	if msg.AtAll {
+		body["content"] = "@everyone"
+		body["allowed_mentions"] = map[string]any{"parse": []string{"everyone"}}
+	}
+	defer func() {
+		d.http.CloseIdleConnections()
+	}()
+	res, err := d.http.NewRequest().
+		SetBody(body).
+		Post(d.url)
+	if err != nil {
+		return fmt.Errorf("discord send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		var result discordResult
+		_ = res.Unmarshal(&result)
+		return fmt.Errorf("discord send error: %s %d %s", res.GetStatus(), result.Code, result.Message)
+	}
+	return nil
+}
+
+// Limit embed 的 description 上限为 4096 个字符, 预留按钮链接的长度
+func (d *sDiscord) Limit() int {
+	return 3500
+}
+
+func discordColor(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 0xE53935
+	case SeverityWarning:
+		return 0xFB8C00
+	default:
+		return 0x43A047
+	}
+}
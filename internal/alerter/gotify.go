@@ -0,0 +1,76 @@
+package alerter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xmapst/logx"
+)
+
+var _ IAlert = (*sGotify)(nil)
+
+// sGotify Gotify 推送, url 为服务地址, ak 为应用的 token
+type sGotify struct {
+	*sBase
+}
+
+type gotifyResult struct {
+	Error            string `json:"error"`
+	ErrorCode        int    `json:"errorCode"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+func (g *sGotify) Send(msg *Message) error {
+	if g.url == "" || g.ak == "" {
+		return fmt.Errorf("gotify url or app token is empty")
+	}
+	text := stripTags(msg.Text)
+	if len(msg.Buttons) > 0 {
+		var links []string
+		for _, b := range msg.Buttons {
+			links = append(links, fmt.Sprintf("[%s](%s)", b.Title, b.URL))
+		}
+		text += "\n\n" + strings.Join(links, " | ")
+	}
+	defer func() {
+		g.http.CloseIdleConnections()
+	}()
+	res, err := g.http.NewRequest().
+		SetHeader("X-Gotify-Key", g.ak).
+		SetBody(map[string]any{
+			"title":    g.title(msg),
+			"message":  text,
+			"priority": gotifyPriority(msg.Severity),
+			"extras": map[string]any{
+				"client::display": map[string]string{
+					"contentType": "text/markdown",
+				},
+			},
+		}).
+		Post(strings.TrimSuffix(g.url, "/") + "/message")
+	if err != nil {
+		return fmt.Errorf("gotify send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		var result gotifyResult
+		_ = res.Unmarshal(&result)
+		return fmt.Errorf("gotify send error: %s %d %s", res.GetStatus(), result.ErrorCode, result.ErrorDescription)
+	}
+	return nil
+}
+
+func (g *sGotify) Limit() int {
+	return 0
+}
+
+func gotifyPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 8
+	case SeverityWarning:
+		return 5
+	default:
+		return 2
+	}
+}
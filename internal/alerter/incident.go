@@ -0,0 +1,61 @@
+package alerter
+
+import (
+	"time"
+)
+
+// IIncident 以单个证书为粒度创建与恢复事件的告警渠道, 如 PagerDuty, Opsgenie
+type IIncident interface {
+	IAlert
+	// Trigger 创建或更新事件, 相同 Key 的事件会被合并
+	Trigger(e *Event) error
+	// Resolve 恢复 Key 对应的事件
+	Resolve(e *Event) error
+}
+
+// IKeepalive 事件需要周期性重新推送才能保持打开的渠道, 如 Alertmanager
+type IKeepalive interface {
+	IIncident
+	// TTL 推送的事件在该时间后未刷新即自动恢复
+	TTL() time.Duration
+}
+
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Event 单个证书的事件
+type Event struct {
+	// 同一证书在多次检查之间保持不变的去重键
+	Key         string            `json:"key"`
+	Summary     string            `json:"summary"`
+	Severity    string            `json:"severity"`
+	Source      string            `json:"source"`
+	Path        string            `json:"path"`
+	DomainName  string            `json:"domain_name"`
+	Status      string            `json:"status"`
+	ExpiredDays int               `json:"expired_days"`
+	NotAfter    time.Time         `json:"not_after"`
+	Serial      string            `json:"serial"`
+	Owner       string            `json:"owner,omitempty"`
+	Team        string            `json:"team,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	// 事件首次打开的时间
+	StartsAt time.Time `json:"starts_at"`
+}
+
+func (e *Event) details() map[string]any {
+	return map[string]any{
+		"path":         e.Path,
+		"domain_name":  e.DomainName,
+		"status":       e.Status,
+		"expired_days": e.ExpiredDays,
+		"not_after":    e.NotAfter.Format(time.RFC3339),
+		"serial":       e.Serial,
+		"owner":        e.Owner,
+		"team":         e.Team,
+		"labels":       e.Labels,
+	}
+}
@@ -0,0 +1,108 @@
+package alerter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+var _ IIncident = (*sJournald)(nil)
+
+// sJournald 通过原生协议写入 journald, 每个证书的属性作为 CERT_ 前缀的字段,
+// 可用 journalctl SYSLOG_IDENTIFIER=cert-checker CERT_STATUS=expired 查询, url 可指定 socket 路径
+type sJournald struct {
+	*sBase
+}
+
+func (j *sJournald) Send(msg *Message) error {
+	return j.write(map[string]string{
+		"MESSAGE":           j.title(msg) + "\n" + plainText(msg.Text),
+		"PRIORITY":          strconv.Itoa(severityCode(msg.Severity)),
+		"CERT_EVENT":        "report",
+		"SYSLOG_IDENTIFIER": syslogAppName,
+	})
+}
+
+func (j *sJournald) Trigger(e *Event) error {
+	return j.write(j.fields(e, "expiring", severityCode(e.Severity)))
+}
+
+func (j *sJournald) Resolve(e *Event) error {
+	return j.write(j.fields(e, "resolved", syslogNotice))
+}
+
+func (j *sJournald) fields(e *Event, event string, priority int) map[string]string {
+	fields := map[string]string{
+		"MESSAGE":           e.Summary,
+		"PRIORITY":          strconv.Itoa(priority),
+		"SYSLOG_IDENTIFIER": syslogAppName,
+		"CERT_EVENT":        event,
+		"CERT_KEY":          e.Key,
+		"CERT_PATH":         e.Path,
+		"CERT_DOMAIN":       e.DomainName,
+		"CERT_STATUS":       e.Status,
+		"CERT_SEVERITY":     e.Severity,
+		"CERT_EXPIRED_DAYS": strconv.Itoa(e.ExpiredDays),
+		"CERT_NOT_AFTER":    e.NotAfter.Format(time.RFC3339),
+		"CERT_SERIAL":       e.Serial,
+	}
+	if e.Owner != "" {
+		fields["CERT_OWNER"] = e.Owner
+	}
+	if e.Team != "" {
+		fields["CERT_TEAM"] = e.Team
+	}
+	for k, v := range e.Labels {
+		fields["CERT_LABEL_"+strings.ToUpper(invalidLabelName.ReplaceAllString(k, "_"))] = v
+	}
+	return fields
+}
+
+func (j *sJournald) write(fields map[string]string) error {
+	var buf bytes.Buffer
+	for _, k := range sortedKeys(fields) {
+		v := fields[k]
+		// 多行的值使用二进制格式: 字段名, 换行, 小端 64 位长度, 值, 换行
+		if strings.Contains(v, "\n") {
+			buf.WriteString(k + "\n")
+			_ = binary.Write(&buf, binary.LittleEndian, uint64(len(v)))
+			buf.WriteString(v + "\n")
+			continue
+		}
+		buf.WriteString(k + "=" + v + "\n")
+	}
+	socket := j.url
+	if socket == "" {
+		socket = journaldSocket
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("journald dial error: %w", err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("journald send error: %w", err)
+	}
+	return nil
+}
+
+// Limit 单个数据报不宜过大, 超出 socket 缓冲区时写入会失败
+func (j *sJournald) Limit() int {
+	return 8192
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package alerter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlTag      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	mdHeading    = regexp.MustCompile(`(?m)^#{1,6}\s*`)
+	mdQuote      = regexp.MustCompile(`(?m)^>\s?`)
+	mdRule       = regexp.MustCompile(`(?m)^_{3,}\s*$`)
+	trailing     = regexp.MustCompile(`(?m)[ \t]+$`)
+	blankLines   = regexp.MustCompile(`\n{3,}`)
+	markdownV2   = strings.NewReplacer(markdownV2Pairs()...)
+	markdownV2Sp = `_*[]()~` + "`" + `>#+-=|{}.!\`
+)
+
+// stripTags 去除模板中钉钉支持但其他渠道不支持的 HTML 标签, 如 <font color=FF0000>
+func stripTags(text string) string {
+	return htmlTag.ReplaceAllString(text, "")
+}
+
+// plainText 将模板渲染出的 markdown 转换为纯文本
+func plainText(text string) string {
+	text = stripTags(text)
+	text = strings.ReplaceAll(text, "**", "")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdQuote.ReplaceAllString(text, "")
+	text = mdRule.ReplaceAllString(text, "")
+	text = trailing.ReplaceAllString(text, "")
+	text = blankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// escapeMarkdownV2 按 Telegram MarkdownV2 的要求转义所有特殊字符
+func escapeMarkdownV2(text string) string {
+	return markdownV2.Replace(text)
+}
+
+func markdownV2Pairs() []string {
+	var pairs []string
+	for _, ch := range markdownV2Sp {
+		pairs = append(pairs, string(ch), `\`+string(ch))
+	}
+	return pairs
+}
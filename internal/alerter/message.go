@@ -0,0 +1,23 @@
+package alerter
+
+// Message 告警消息, 不支持的字段由各渠道忽略
+type Message struct {
+	// 标题, 为空时使用默认标题
+	Title string
+	Text  string
+	// 需要提醒的手机号与用户 ID
+	AtMobiles []string
+	AtUserIds []string
+	AtAll     bool
+	// 报告中最严重的级别, 用于设置推送优先级
+	Severity string
+	// 消息下方的跳转按钮
+	Buttons []*Button
+	// 按钮是否竖直排列
+	Vertical bool
+}
+
+type Button struct {
+	Title string
+	URL   string
+}
@@ -0,0 +1,81 @@
+package alerter
+
+import (
+	"fmt"
+
+	"github.com/xmapst/logx"
+)
+
+const ntfyApiUrl = "https://ntfy.sh"
+
+var _ IAlert = (*sNtfy)(nil)
+
+// sNtfy ntfy 推送, ak 为 topic, sk 为可选的 access token, 自建服务时通过 url 指定
+type sNtfy struct {
+	*sBase
+}
+
+type ntfyResult struct {
+	Code  int    `json:"code"`
+	Error string `json:"error"`
+}
+
+func (n *sNtfy) Send(msg *Message) error {
+	if n.ak == "" {
+		return fmt.Errorf("ntfy topic is empty")
+	}
+	base := n.url
+	if base == "" {
+		base = ntfyApiUrl
+	}
+	body := map[string]any{
+		"topic":    n.ak,
+		"title":    n.title(msg),
+		"message":  stripTags(msg.Text),
+		"markdown": true,
+		"priority": ntfyPriority(msg.Severity),
+		"tags":     []string{"lock"},
+	}
+	var actions []map[string]any
+	for _, b := range msg.Buttons {
+		actions = append(actions, map[string]any{"action": "view", "label": b.Title, "url": b.URL})
+	}
+	if len(actions) > 0 {
+		body["actions"] = actions
+	}
+	r := n.http.NewRequest().SetBody(body)
+	if n.sk != "" {
+		r.SetBearerAuthToken(n.sk)
+	}
+	defer func() {
+		n.http.CloseIdleConnections()
+	}()
+	// 以 JSON 格式发布, 标题等字段不受请求头只能使用 ASCII 的限制
+	res, err := r.Post(base)
+	if err != nil {
+		return fmt.Errorf("ntfy send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		var result ntfyResult
+		_ = res.Unmarshal(&result)
+		return fmt.Errorf("ntfy send error: %s %d %s", res.GetStatus(), result.Code, result.Error)
+	}
+	return nil
+}
+
+// Limit 超过 4096 字节的消息会被 ntfy 转为附件
+func (n *sNtfy) Limit() int {
+	return 4096
+}
+
+func ntfyPriority(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 5
+	case SeverityWarning:
+		return 4
+	default:
+		return 3
+	}
+}
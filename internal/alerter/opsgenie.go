@@ -0,0 +1,100 @@
+package alerter
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/xmapst/logx"
+)
+
+const opsgenieApiUrl = "https://api.opsgenie.com"
+
+var _ IIncident = (*sOpsgenie)(nil)
+
+// sOpsgenie Opsgenie Alert API, ak 为 API 集成的 key, 欧洲区通过 url 指定 https://api.eu.opsgenie.com
+type sOpsgenie struct {
+	*sBase
+}
+
+type opsgenieResult struct {
+	Message string `json:"message"`
+}
+
+// Send 以 P5 优先级发送文本消息
+func (o *sOpsgenie) Send(msg *Message) error {
+	return o.post("/v2/alerts", map[string]any{
+		"message":     o.title(msg),
+		"description": msg.Text,
+		"priority":    "P5",
+		"source":      "cert-checker",
+	})
+}
+
+func (o *sOpsgenie) Trigger(e *Event) error {
+	var tags []string
+	for k, v := range e.Labels {
+		tags = append(tags, k+":"+v)
+	}
+	details := map[string]string{}
+	for k, v := range e.details() {
+		if k == "labels" {
+			continue
+		}
+		details[k] = fmt.Sprint(v)
+	}
+	return o.post("/v2/alerts", map[string]any{
+		"message":     e.Summary,
+		"alias":       e.Key,
+		"description": e.Summary,
+		"priority":    opsgeniePriority(e.Severity),
+		"source":      e.Source,
+		"entity":      e.DomainName,
+		"tags":        tags,
+		"details":     details,
+	})
+}
+
+func (o *sOpsgenie) Resolve(e *Event) error {
+	return o.post(fmt.Sprintf("/v2/alerts/%s/close?identifierType=alias", url.PathEscape(e.Key)), map[string]any{
+		"source": e.Source,
+		"note":   e.Summary,
+	})
+}
+
+func (o *sOpsgenie) post(path string, body map[string]any) error {
+	if o.ak == "" {
+		return fmt.Errorf("opsgenie api key is empty")
+	}
+	base := o.url
+	if base == "" {
+		base = opsgenieApiUrl
+	}
+	defer func() {
+		o.http.CloseIdleConnections()
+	}()
+	res, err := o.http.NewRequest().
+		SetHeader("Authorization", "GenieKey "+o.ak).
+		SetBody(body).
+		Post(base + path)
+	if err != nil {
+		return fmt.Errorf("opsgenie send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		var result opsgenieResult
+		_ = res.Unmarshal(&result)
+		return fmt.Errorf("opsgenie send error: %s %s", res.GetStatus(), result.Message)
+	}
+	return nil
+}
+
+func opsgeniePriority(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "P1"
+	case SeverityWarning:
+		return "P3"
+	default:
+		return "P5"
+	}
+}
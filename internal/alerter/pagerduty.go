@@ -0,0 +1,84 @@
+package alerter
+
+import (
+	"fmt"
+
+	"github.com/xmapst/logx"
+)
+
+const pagerdutyEventsUrl = "https://events.pagerduty.com/v2/enqueue"
+
+var _ IIncident = (*sPagerDuty)(nil)
+
+// sPagerDuty PagerDuty Events API v2, ak 为服务集成的 routing key
+type sPagerDuty struct {
+	*sBase
+}
+
+type pagerdutyResult struct {
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Errors  []string `json:"errors"`
+}
+
+// Send 以 info 级别事件发送文本消息
+func (d *sPagerDuty) Send(msg *Message) error {
+	return d.enqueue(map[string]any{
+		"event_action": "trigger",
+		"payload": map[string]any{
+			"summary":  d.title(msg),
+			"source":   "cert-checker",
+			"severity": SeverityInfo,
+			"custom_details": map[string]any{
+				"text": msg.Text,
+			},
+		},
+	})
+}
+
+func (d *sPagerDuty) Trigger(e *Event) error {
+	return d.enqueue(map[string]any{
+		"event_action": "trigger",
+		"dedup_key":    e.Key,
+		"payload": map[string]any{
+			"summary":        e.Summary,
+			"source":         e.Source,
+			"severity":       e.Severity,
+			"component":      e.DomainName,
+			"group":          e.Team,
+			"custom_details": e.details(),
+		},
+	})
+}
+
+func (d *sPagerDuty) Resolve(e *Event) error {
+	return d.enqueue(map[string]any{
+		"event_action": "resolve",
+		"dedup_key":    e.Key,
+	})
+}
+
+func (d *sPagerDuty) enqueue(body map[string]any) error {
+	if d.ak == "" {
+		return fmt.Errorf("pagerduty routing key is empty")
+	}
+	url := d.url
+	if url == "" {
+		url = pagerdutyEventsUrl
+	}
+	defer func() {
+		d.http.CloseIdleConnections()
+	}()
+	body["routing_key"] = d.ak
+	res, err := d.http.NewRequest().SetBody(body).Post(url)
+	if err != nil {
+		return fmt.Errorf("pagerduty send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !res.IsSuccessState() {
+		var result pagerdutyResult
+		_ = res.Unmarshal(&result)
+		return fmt.Errorf("pagerduty send error: %s %s %v", res.GetStatus(), result.Message, result.Errors)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package alerter
+
+import (
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/i18n"
+)
+
+// maxBackoff 重试间隔上限
+const maxBackoff = time.Minute
+
+// SendWithRetry 发送失败时按指数退避重试, retries 为失败后的重试次数
+func SendWithRetry(alert IAlert, msg *Message, retries int, backoff time.Duration) error {
+	return Retry(retries, backoff, func() error {
+		return alert.Send(msg)
+	})
+}
+
+// Retry 执行 fn, 失败时按指数退避重试, 用于发送消息以及创建或恢复事件
+func Retry(retries int, backoff time.Duration, fn func() error) (err error) {
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= retries {
+			return err
+		}
+		logx.Warnln(i18n.T(i18n.LogSendRetry, backoff, err))
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
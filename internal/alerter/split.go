@@ -0,0 +1,44 @@
+package alerter
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Split 按行将文本拆分为不超过 limit 字节的多段, 单行超过 limit 时按字符截断, limit <= 0 表示不拆分;
+// limit 小于单个字符的字节数时该字符单独成段
+func Split(text string, limit int) []string {
+	if limit <= 0 || len(text) <= limit {
+		return []string{text}
+	}
+	var (
+		chunks []string
+		buf    strings.Builder
+	)
+	flush := func() {
+		if buf.Len() > 0 {
+			chunks = append(chunks, buf.String())
+			buf.Reset()
+		}
+	}
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if buf.Len()+len(line) > limit {
+			flush()
+		}
+		for len(line) > limit {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			// limit 小于一个字符的长度时每段至少包含一个字符, 否则无法前进
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(line)
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		buf.WriteString(line)
+	}
+	flush()
+	return chunks
+}
@@ -0,0 +1,33 @@
+package alerter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"abc", 0, []string{"abc"}},
+		{"abc", 10, []string{"abc"}},
+		{"ab\ncd\nef", 6, []string{"ab\ncd\n", "ef"}},
+		{"abcdef", 4, []string{"abcd", "ef"}},
+		{"证书过期", 7, []string{"证书", "过期"}},
+		// limit 小于单个字符的字节数
+		{"证书", 2, []string{"证", "书"}},
+		{"a证b", 1, []string{"a", "证", "b"}},
+	}
+	for _, tt := range tests {
+		got := Split(tt.text, tt.limit)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+		if joined := strings.Join(got, ""); joined != tt.text {
+			t.Errorf("Split(%q, %d) lost text: %q", tt.text, tt.limit, joined)
+		}
+	}
+}
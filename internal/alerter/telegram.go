@@ -0,0 +1,76 @@
+package alerter
+
+import (
+	"fmt"
+
+	"github.com/xmapst/logx"
+)
+
+const telegramApiUrl = "https://api.telegram.org"
+
+var _ IAlert = (*sTelegram)(nil)
+
+// sTelegram Telegram Bot API, ak 为 bot token, sk 为 chat id, 自建 Bot API 服务时通过 url 指定
+type sTelegram struct {
+	*sBase
+}
+
+type telegramResult struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (t *sTelegram) Send(msg *Message) error {
+	if t.ak == "" || t.sk == "" {
+		return fmt.Errorf("telegram bot token or chat id is empty")
+	}
+	base := t.url
+	if base == "" {
+		base = telegramApiUrl
+	}
+	text := fmt.Sprintf("*%s*\n\n%s", escapeMarkdownV2(t.title(msg)), escapeMarkdownV2(plainText(msg.Text)))
+	body := map[string]any{
+		"chat_id":    t.sk,
+		"text":       text,
+		"parse_mode": "MarkdownV2",
+		// 续期等通知静默推送
+		"disable_notification": msg.Severity == SeverityInfo,
+	}
+	var buttons []map[string]string
+	for _, b := range msg.Buttons {
+		buttons = append(buttons, map[string]string{"text": b.Title, "url": b.URL})
+	}
+	if len(buttons) > 0 {
+		keyboard := [][]map[string]string{buttons}
+		if msg.Vertical {
+			keyboard = nil
+			for _, b := range buttons {
+				keyboard = append(keyboard, []map[string]string{b})
+			}
+		}
+		body["reply_markup"] = map[string]any{"inline_keyboard": keyboard}
+	}
+	defer func() {
+		t.http.CloseIdleConnections()
+	}()
+	var result telegramResult
+	res, err := t.http.NewRequest().
+		SetBody(body).
+		SetSuccessResult(&result).
+		SetErrorResult(&result).
+		Post(fmt.Sprintf("%s/bot%s/sendMessage", base, t.ak))
+	if err != nil {
+		return fmt.Errorf("telegram send error: %w", err)
+	}
+	logx.Debugln(res.String())
+	if !result.Ok {
+		return fmt.Errorf("telegram send error: %s %d %s", res.GetStatus(), result.ErrorCode, result.Description)
+	}
+	return nil
+}
+
+// Limit 单条消息上限为 4096 个字符, 转义会增加长度, 因此预留一半
+func (t *sTelegram) Limit() int {
+	return 2048
+}
@@ -0,0 +1,422 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/busybox-org/cert-checker/internal/i18n"
+)
+
+// DefaultChannel 由命令行参数生成的告警渠道名称
+const DefaultChannel = "default"
+
+// DefaultTier 由 --days 生成的告警级别名称
+const DefaultTier = "warning"
+
+// DefaultJob 未配置任务时由全局目标组成的任务名称
+const DefaultJob = "default"
+
+// DefaultHistoryGap 部署记录中视为缺失的默认未检查时长
+const DefaultHistoryGap = 48 * time.Hour
+
+type Config struct {
+	Lang          string        `yaml:"lang"`
+	StateFile     string        `yaml:"state_file"`
+	Renotify      time.Duration `yaml:"renotify"`
+	MetricsListen string        `yaml:"metrics_listen"`
+	// 管理接口监听地址, 仅允许 unix socket(unix:///path) 或回环地址
+	APIListen string `yaml:"api_listen"`
+	// 网页控制台监听地址
+	WebListen string `yaml:"web_listen"`
+	// 启动后立即执行一次检查
+	RunOnStart bool `yaml:"run_on_start"`
+	// 启动时执行一次检查并向所有渠道发送测试消息, 失败时拒绝启动
+	Validate bool `yaml:"validate"`
+	// 监听启用任务的目标路径, 证书文件变化后立即重新检查
+	Watch bool `yaml:"watch"`
+	// 并发解析证书文件的协程数, 0 表示 CPU 核数
+	Workers int `yaml:"workers"`
+	// 单个证书文件的检查超时
+	FileTimeout time.Duration `yaml:"file_timeout"`
+	// 文件的设备号, inode, 大小与修改时间均未变化时复用上次的解析结果
+	ParseCache bool `yaml:"parse_cache"`
+	// 复用解析结果前还需校验文件内容的摘要, 仍需读取文件但无需重新解析
+	VerifyCache bool `yaml:"verify_cache"`
+	// 路径上的证书超过该时间未检查到时, 再次检查到即开始新的部署记录, 以记录中间的缺失
+	HistoryGap time.Duration `yaml:"history_gap"`
+	// 任务未指定定时规则时使用的默认规则
+	Cron string `yaml:"cron"`
+	// 目标未指定后缀时使用的默认文件后缀
+	Suffix   string     `yaml:"suffix"`
+	Targets  []*Target  `yaml:"targets"`
+	Channels []*Channel `yaml:"channels"`
+	// 任务未配置告警级别时使用的级别
+	Tiers []*Tier `yaml:"tiers"`
+	// 告警路由, 未配置或未命中时使用级别上的渠道, 任务未配置路由时使用
+	Route *Route `yaml:"route"`
+	// 检查任务, 未配置时由 targets 与 cron 组成默认任务
+	Jobs []*Job `yaml:"jobs"`
+	// 负责人的联系方式, key 为目标上的 owner
+	Owners map[string]*Owner `yaml:"owners"`
+}
+
+// Owner 负责人联系方式, 用于在告警中提醒负责人
+type Owner struct {
+	Mobile string `yaml:"mobile"`
+	UserID string `yaml:"user_id"`
+}
+
+// ActionCard 在消息下方附加跳转按钮, 如处理手册或证书续期工单
+type ActionCard struct {
+	Vertical bool      `yaml:"vertical"`
+	Buttons  []*Button `yaml:"buttons"`
+}
+
+type Button struct {
+	Title string `yaml:"title"`
+	URL   string `yaml:"url"`
+}
+
+// Target 检查目标, 可以是文件或目录
+type Target struct {
+	Path   string `yaml:"path"`
+	Suffix string `yaml:"suffix"`
+	// 覆盖最宽松级别的天数, 0 表示沿用级别配置
+	Days int `yaml:"days"`
+	// 按级别名称覆盖天数
+	Tiers  map[string]int    `yaml:"tiers"`
+	Owner  string            `yaml:"owner"`
+	Team   string            `yaml:"team"`
+	Labels map[string]string `yaml:"labels"`
+}
+
+// Channel 告警渠道
+type Channel struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	URL  string `yaml:"url"`
+	AK   string `yaml:"ak"`
+	SK   string `yaml:"sk"`
+	Lang string `yaml:"lang"`
+	// 发送失败后的重试次数及首次重试间隔, 间隔按指数增长, 重试次数默认为 3, 负数表示不重试
+	Retries int           `yaml:"retries"`
+	Backoff time.Duration `yaml:"backoff"`
+	// 是否提醒报告中证书的负责人
+	MentionOwners bool `yaml:"mention_owners"`
+	// 报告中包含这些级别的证书时提醒所有人
+	AtAll      []string    `yaml:"at_all"`
+	ActionCard *ActionCard `yaml:"action_card"`
+	// 报告超过渠道单条消息上限时拆分发送的最多条数, 0 表示不限制,
+	// 超出部分省略并附上 ReportURL 指向的完整报告
+	MaxMessages int    `yaml:"max_messages"`
+	ReportURL   string `yaml:"report_url"`
+	// 静默时段与维护窗口使用的时区, 默认为本地时区
+	Timezone    string      `yaml:"timezone"`
+	QuietHours  *QuietHours `yaml:"quiet_hours"`
+	Maintenance []*Window   `yaml:"maintenance"`
+
+	location *time.Location
+}
+
+// Quiet 判断渠道当前是否处于静默时段或维护窗口
+func (ch *Channel) Quiet(now time.Time) bool {
+	now = now.In(ch.location)
+	if ch.QuietHours != nil && ch.QuietHours.contains(now) {
+		return true
+	}
+	for _, w := range ch.Maintenance {
+		if w.contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tier 告警级别, 剩余天数不超过 Days 时命中, 多个级别命中时取 Days 最小的
+type Tier struct {
+	Name     string        `yaml:"name"`
+	Days     int           `yaml:"days"`
+	Channels []string      `yaml:"channels"`
+	Repeat   time.Duration `yaml:"repeat"`
+	// 严重级别的通知不受静默时段与维护窗口限制
+	Critical bool `yaml:"critical"`
+}
+
+// Load 先由命令行参数生成默认配置, 再使用 --config 指定的文件覆盖, 并校验配置
+func Load(flags *pflag.FlagSet) (*Config, error) {
+	c, err := Parse(flags)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.complete(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Parse 与 Load 相同但不校验配置, 供只需要部分配置的子命令使用
+func Parse(flags *pflag.FlagSet) (*Config, error) {
+	c := fromFlags(flags)
+	if name := lookup(flags, "config"); name != "" {
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err = yaml.Unmarshal(content, c); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", name, err)
+		}
+	}
+	return c, nil
+}
+
+// fromFlags 子命令上不存在的参数取零值
+func fromFlags(flags *pflag.FlagSet) *Config {
+	days, _ := flags.GetInt("days")
+	renotify, _ := flags.GetDuration("renotify")
+	paths, _ := flags.GetStringSlice("path")
+	runOnStart, _ := flags.GetBool("run_on_start")
+	validate, _ := flags.GetBool("validate")
+	watch, _ := flags.GetBool("watch")
+	workers, _ := flags.GetInt("workers")
+	fileTimeout, _ := flags.GetDuration("file_timeout")
+	parseCache, _ := flags.GetBool("parse_cache")
+	verifyCache, _ := flags.GetBool("verify_cache")
+	historyGap, _ := flags.GetDuration("history_gap")
+	var targets []*Target
+	for _, path := range paths {
+		targets = append(targets, &Target{
+			Path: path,
+		})
+	}
+	return &Config{
+		Lang:          lookup(flags, "lang"),
+		StateFile:     lookup(flags, "state_file"),
+		Renotify:      renotify,
+		MetricsListen: lookup(flags, "metrics_listen"),
+		APIListen:     lookup(flags, "api_listen"),
+		WebListen:     lookup(flags, "web_listen"),
+		RunOnStart:    runOnStart,
+		Validate:      validate,
+		Watch:         watch,
+		Workers:       workers,
+		FileTimeout:   fileTimeout,
+		ParseCache:    parseCache,
+		VerifyCache:   verifyCache,
+		HistoryGap:    historyGap,
+		Cron:          lookup(flags, "cron"),
+		Suffix:        lookup(flags, "suffix"),
+		Targets:       targets,
+		Channels: []*Channel{
+			{
+				Name: DefaultChannel,
+				Type: lookup(flags, "alert_type"),
+				AK:   lookup(flags, "alert_ak"),
+				SK:   lookup(flags, "alert_sk"),
+				Lang: lookup(flags, "alert_lang"),
+			},
+		},
+		Tiers: []*Tier{
+			{
+				Name: DefaultTier,
+				Days: days,
+			},
+		},
+	}
+}
+
+func lookup(flags *pflag.FlagSet, name string) string {
+	flag := flags.Lookup(name)
+	if flag == nil {
+		return ""
+	}
+	return flag.Value.String()
+}
+
+// complete 填充默认值并校验配置
+func (c *Config) complete() error {
+	if !i18n.Supported(c.Lang) {
+		return fmt.Errorf("unsupported language: %s", c.Lang)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("workers must not be negative")
+	}
+	if c.FileTimeout < 0 {
+		return fmt.Errorf("file_timeout must not be negative")
+	}
+	switch {
+	case c.HistoryGap == 0:
+		c.HistoryGap = DefaultHistoryGap
+	case c.HistoryGap < 0:
+		return fmt.Errorf("history_gap must not be negative")
+	}
+	var names []string
+	for _, ch := range c.Channels {
+		if ch.Name == "" {
+			return fmt.Errorf("channel name is empty")
+		}
+		if slices.Contains(names, ch.Name) {
+			return fmt.Errorf("duplicate channel %s", ch.Name)
+		}
+		names = append(names, ch.Name)
+		if ch.Lang == "" {
+			ch.Lang = c.Lang
+		}
+		if !i18n.Supported(ch.Lang) {
+			return fmt.Errorf("channel %s: unsupported language: %s", ch.Name, ch.Lang)
+		}
+		switch ch.Type {
+		case "dingtalk", "pagerduty", "opsgenie", "ntfy":
+			if ch.AK == "" {
+				return fmt.Errorf("channel %s: %s ak is empty", ch.Name, ch.Type)
+			}
+		case "alertmanager", "discord", "syslog":
+			if ch.URL == "" {
+				return fmt.Errorf("channel %s: %s url is empty", ch.Name, ch.Type)
+			}
+		case "telegram":
+			if ch.AK == "" || ch.SK == "" {
+				return fmt.Errorf("channel %s: %s ak and sk are required", ch.Name, ch.Type)
+			}
+		case "gotify":
+			if ch.URL == "" || ch.AK == "" {
+				return fmt.Errorf("channel %s: %s url and ak are required", ch.Name, ch.Type)
+			}
+		}
+		switch {
+		case ch.Retries == 0:
+			ch.Retries = 3
+		case ch.Retries < 0:
+			ch.Retries = 0
+		}
+		if ch.ActionCard != nil {
+			for _, b := range ch.ActionCard.Buttons {
+				if b.Title == "" || b.URL == "" {
+					return fmt.Errorf("channel %s: action card button requires title and url", ch.Name)
+				}
+			}
+			// 钉钉的 ActionCard 消息不支持 @ 提醒, 同时配置时提醒会被静默丢弃
+			if ch.Type == "dingtalk" && len(ch.ActionCard.Buttons) > 0 && (ch.MentionOwners || len(ch.AtAll) > 0) {
+				return fmt.Errorf("channel %s: dingtalk action_card does not support mention_owners or at_all", ch.Name)
+			}
+		}
+		if ch.MaxMessages < 0 {
+			return fmt.Errorf("channel %s: max_messages must not be negative", ch.Name)
+		}
+		if ch.Backoff <= 0 {
+			ch.Backoff = 2 * time.Second
+		}
+		ch.location = time.Local
+		if ch.Timezone != "" {
+			loc, err := time.LoadLocation(ch.Timezone)
+			if err != nil {
+				return fmt.Errorf("channel %s: %w", ch.Name, err)
+			}
+			ch.location = loc
+		}
+		if ch.QuietHours != nil {
+			if err := ch.QuietHours.compile(); err != nil {
+				return fmt.Errorf("channel %s: quiet hours: %w", ch.Name, err)
+			}
+		}
+		for _, w := range ch.Maintenance {
+			if err := w.compile(ch.location); err != nil {
+				return fmt.Errorf("channel %s: maintenance: %w", ch.Name, err)
+			}
+		}
+	}
+	if err := completeTiers(c.Tiers); err != nil {
+		return err
+	}
+	for name, o := range c.Owners {
+		if o == nil || (o.Mobile == "" && o.UserID == "") {
+			return fmt.Errorf("owner %s: mobile or user_id is required", name)
+		}
+	}
+	if c.APIListen != "" && !strings.HasPrefix(c.APIListen, "unix://") {
+		host, _, err := net.SplitHostPort(c.APIListen)
+		if err != nil {
+			return fmt.Errorf("api listen: %w", err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return fmt.Errorf("api listen: %s is not a loopback address", c.APIListen)
+		}
+	}
+	// 未配置任务时, 由全局的目标与定时规则组成默认任务
+	if len(c.Jobs) == 0 {
+		c.Jobs = []*Job{
+			{
+				Name:    DefaultJob,
+				Targets: c.Targets,
+			},
+		}
+	}
+	var jobs []string
+	for _, j := range c.Jobs {
+		if j.Name == "" {
+			return fmt.Errorf("job name is empty")
+		}
+		if slices.Contains(jobs, j.Name) {
+			return fmt.Errorf("duplicate job %s", j.Name)
+		}
+		jobs = append(jobs, j.Name)
+		if err := j.complete(c, names); err != nil {
+			return fmt.Errorf("job %s: %w", j.Name, err)
+		}
+	}
+	if len(c.EnabledJobs()) == 0 {
+		return fmt.Errorf("at least one enabled job is required")
+	}
+	for _, ch := range c.Channels {
+		for _, severity := range ch.AtAll {
+			if severity != "expired" && !slices.ContainsFunc(c.Jobs, func(j *Job) bool { return j.Tier(severity) != nil }) {
+				return fmt.Errorf("channel %s: unknown severity %s", ch.Name, severity)
+			}
+		}
+	}
+	return nil
+}
+
+// completeTiers 校验告警级别并按剩余天数升序排列, 最严重的级别在前
+func completeTiers(tiers []*Tier) error {
+	if len(tiers) == 0 {
+		return fmt.Errorf("at least one tier is required")
+	}
+	var names []string
+	for _, t := range tiers {
+		switch t.Name {
+		case "":
+			return fmt.Errorf("tier name is empty")
+		case "ok", "expired":
+			return fmt.Errorf("tier name %s is reserved", t.Name)
+		}
+		if slices.Contains(names, t.Name) {
+			return fmt.Errorf("duplicate tier %s", t.Name)
+		}
+		names = append(names, t.Name)
+		if t.Days < 0 {
+			return fmt.Errorf("tier %s: days must not be negative", t.Name)
+		}
+	}
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].Days < tiers[j].Days
+	})
+	return nil
+}
+
+// Channel 按名称查找告警渠道
+func (c *Config) Channel(name string) *Channel {
+	for _, ch := range c.Channels {
+		if ch.Name == name {
+			return ch
+		}
+	}
+	return nil
+}
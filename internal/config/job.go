@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+)
+
+// Job 独立的检查任务, 拥有各自的目标, 定时规则, 告警级别, 渠道与报告模板
+type Job struct {
+	Name string `yaml:"name"`
+	// 未设置时默认启用
+	Enabled *bool `yaml:"enabled"`
+	// 定时规则, 未设置时使用全局的 cron
+	Cron    string    `yaml:"cron"`
+	Targets []*Target `yaml:"targets"`
+	// 告警级别, 未设置时使用全局的级别
+	Tiers []*Tier `yaml:"tiers"`
+	// 级别未指定渠道时使用的渠道, 未设置时发送到所有渠道
+	Channels []string `yaml:"channels"`
+	// 告警路由, 未设置时使用全局的路由
+	Route *Route `yaml:"route"`
+	// 报告模板, 未设置时使用内置模板
+	Template string `yaml:"template"`
+}
+
+// IsEnabled 判断任务是否启用
+func (j *Job) IsEnabled() bool {
+	return j.Enabled == nil || *j.Enabled
+}
+
+// EnabledJobs 返回所有启用的任务
+func (c *Config) EnabledJobs() []*Job {
+	var res []*Job
+	for _, j := range c.Jobs {
+		if j.IsEnabled() {
+			res = append(res, j)
+		}
+	}
+	return res
+}
+
+// Job 按名称查找任务
+func (c *Config) Job(name string) *Job {
+	for _, j := range c.Jobs {
+		if j.Name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// complete 填充任务的默认值并校验, channels 为所有渠道名称
+func (j *Job) complete(c *Config, channels []string) error {
+	if j.Cron == "" {
+		j.Cron = c.Cron
+	}
+	if j.Cron == "" {
+		return fmt.Errorf("cron is empty")
+	}
+	for _, name := range j.Channels {
+		if c.Channel(name) == nil {
+			return fmt.Errorf("unknown channel %s", name)
+		}
+	}
+	if len(j.Channels) > 0 {
+		channels = j.Channels
+	}
+	if len(j.Tiers) == 0 {
+		// 复制全局级别, 以便按任务填充默认渠道
+		for _, t := range c.Tiers {
+			tier := *t
+			j.Tiers = append(j.Tiers, &tier)
+		}
+	} else if err := completeTiers(j.Tiers); err != nil {
+		return err
+	}
+	for _, t := range j.Tiers {
+		if t.Repeat <= 0 {
+			t.Repeat = c.Renotify
+		}
+		if len(t.Channels) == 0 {
+			t.Channels = channels
+		}
+		for _, name := range t.Channels {
+			if c.Channel(name) == nil {
+				return fmt.Errorf("tier %s: unknown channel %s", t.Name, name)
+			}
+		}
+	}
+	if j.Route == nil {
+		j.Route = c.Route
+	}
+	if j.Route != nil {
+		if err := j.Route.compile(c, j); err != nil {
+			return err
+		}
+	}
+	if len(j.Targets) == 0 {
+		return fmt.Errorf("at least one target path is required")
+	}
+	for _, t := range j.Targets {
+		if t.Path == "" {
+			return fmt.Errorf("target path is empty")
+		}
+		if t.Suffix == "" {
+			t.Suffix = c.Suffix
+		}
+		if t.Days < 0 {
+			return fmt.Errorf("target %s: days must not be negative", t.Path)
+		}
+		for name, days := range t.Tiers {
+			if j.Tier(name) == nil {
+				return fmt.Errorf("target %s: unknown tier %s", t.Path, name)
+			}
+			if days < 0 {
+				return fmt.Errorf("target %s: days of tier %s must not be negative", t.Path, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Tier 按名称查找告警级别
+func (j *Job) Tier(name string) *Tier {
+	for _, t := range j.Tiers {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Threshold 返回目标在指定级别下生效的天数
+func (j *Job) Threshold(t *Target, tier *Tier) int {
+	if days, ok := t.Tiers[tier.Name]; ok {
+		return days
+	}
+	if t.Days > 0 && tier == j.Tiers[len(j.Tiers)-1] {
+		return t.Days
+	}
+	return tier.Days
+}
+
+// Classify 返回目标剩余天数命中的告警级别, 多个级别命中时取生效天数最小的,
+// 已过期时即为最严重的级别, 未命中返回 nil
+func (j *Job) Classify(t *Target, expiredDays int) *Tier {
+	var (
+		hit     *Tier
+		hitDays int
+	)
+	for _, tier := range j.Tiers {
+		days := j.Threshold(t, tier)
+		if expiredDays <= days && (hit == nil || days < hitDays) {
+			hit, hitDays = tier, days
+		}
+	}
+	return hit
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/glob"
+)
+
+// Route 告警路由节点, 节点命中后依次匹配子节点, 命中的子节点默认终止后续匹配,
+// 设置 Continue 时继续匹配兄弟节点, 没有子节点命中时使用本节点的渠道
+type Route struct {
+	// 按标签精确匹配, owner 与 team 也可作为标签使用
+	Match map[string]string `yaml:"match"`
+	// 按标签正则匹配
+	MatchRE map[string]string `yaml:"match_re"`
+	// 路径通配符, * 不跨越目录, ** 匹配任意层级
+	Path string `yaml:"path"`
+	// 域名正则
+	Domain string `yaml:"domain"`
+	// 告警级别名称或 expired
+	Severity []string `yaml:"severity"`
+	Channels []string `yaml:"channels"`
+	Continue bool     `yaml:"continue"`
+	Routes   []*Route `yaml:"routes"`
+
+	matchRE map[string]*regexp.Regexp
+	path    *regexp.Regexp
+	domain  *regexp.Regexp
+}
+
+func (r *Route) compile(c *Config, j *Job) (err error) {
+	r.matchRE = make(map[string]*regexp.Regexp, len(r.MatchRE))
+	for k, v := range r.MatchRE {
+		if r.matchRE[k], err = regexp.Compile("^(?:" + v + ")$"); err != nil {
+			return fmt.Errorf("route match_re %s: %w", k, err)
+		}
+	}
+	if r.Path != "" {
+		if r.path, err = glob.Compile(r.Path); err != nil {
+			return fmt.Errorf("route path %s: %w", r.Path, err)
+		}
+	}
+	if r.Domain != "" {
+		if r.domain, err = regexp.Compile(r.Domain); err != nil {
+			return fmt.Errorf("route domain %s: %w", r.Domain, err)
+		}
+	}
+	for _, severity := range r.Severity {
+		if severity != "expired" && j.Tier(severity) == nil {
+			return fmt.Errorf("route: unknown severity %s", severity)
+		}
+	}
+	for _, name := range r.Channels {
+		if c.Channel(name) == nil {
+			return fmt.Errorf("route: unknown channel %s", name)
+		}
+	}
+	for _, child := range r.Routes {
+		if err = child.compile(c, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve 返回结果应发送到的告警渠道, 未命中任何带渠道的节点时返回 nil
+func (r *Route) Resolve(res *checker.Response, severity string) []string {
+	var channels []string
+	r.walk(res, severity, &channels)
+	return channels
+}
+
+func (r *Route) walk(res *checker.Response, severity string, channels *[]string) {
+	var matched bool
+	for _, child := range r.Routes {
+		if !child.matches(res, severity) {
+			continue
+		}
+		matched = true
+		child.walk(res, severity, channels)
+		if !child.Continue {
+			break
+		}
+	}
+	if matched {
+		return
+	}
+	for _, name := range r.Channels {
+		if !slices.Contains(*channels, name) {
+			*channels = append(*channels, name)
+		}
+	}
+}
+
+func (r *Route) matches(res *checker.Response, severity string) bool {
+	for k, v := range r.Match {
+		if label(res, k) != v {
+			return false
+		}
+	}
+	for k, re := range r.matchRE {
+		if !re.MatchString(label(res, k)) {
+			return false
+		}
+	}
+	if r.path != nil && !r.path.MatchString(res.Path) {
+		return false
+	}
+	if r.domain != nil && !r.domain.MatchString(res.DomainName) {
+		return false
+	}
+	if len(r.Severity) > 0 && !slices.Contains(r.Severity, severity) {
+		return false
+	}
+	return true
+}
+
+func label(res *checker.Response, key string) string {
+	if v, ok := res.Labels[key]; ok {
+		return v
+	}
+	switch key {
+	case "owner":
+		return res.Owner
+	case "team":
+		return res.Team
+	}
+	return ""
+}
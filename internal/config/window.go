@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// QuietHours 每日的静默时段, 格式为 15:04, End 早于 Start 时跨越零点
+type QuietHours struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+
+	start int
+	end   int
+}
+
+// Window 维护窗口, 格式为 2006-01-02 15:04 或 RFC3339
+type Window struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+
+	start time.Time
+	end   time.Time
+}
+
+func (q *QuietHours) compile() (err error) {
+	if q.start, err = parseClock(q.Start); err != nil {
+		return err
+	}
+	if q.end, err = parseClock(q.End); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q *QuietHours) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if q.start <= q.end {
+		return m >= q.start && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
+
+func (w *Window) compile(loc *time.Location) (err error) {
+	if w.start, err = parseTime(w.Start, loc); err != nil {
+		return err
+	}
+	if w.end, err = parseTime(w.End, loc); err != nil {
+		return err
+	}
+	if !w.end.After(w.start) {
+		return fmt.Errorf("window end %s is not after start %s", w.End, w.Start)
+	}
+	return nil
+}
+
+func (w *Window) contains(t time.Time) bool {
+	return !t.Before(w.start) && t.Before(w.end)
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: %w", s, err)
+	}
+	return t, nil
+}
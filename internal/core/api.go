@@ -0,0 +1,215 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+)
+
+// snapshot 任务最近一次检查的结果
+type snapshot struct {
+	Job        string              `json:"job"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	Error      string              `json:"error,omitempty"`
+	Results    []*checker.Response `json:"results"`
+}
+
+type apiTarget struct {
+	Job    string            `json:"job"`
+	Path   string            `json:"path"`
+	Suffix string            `json:"suffix"`
+	Owner  string            `json:"owner,omitempty"`
+	Team   string            `json:"team,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// 各级别生效的天数
+	Thresholds map[string]int `json:"thresholds"`
+}
+
+type apiEntry struct {
+	ID   cron.EntryID `json:"id"`
+	Name string       `json:"name"`
+	Spec string       `json:"spec"`
+	Next time.Time    `json:"next"`
+	Prev time.Time    `json:"prev,omitzero"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// schedule 注册定时任务并记录名称, 供管理接口查询
+func (p *sProgram) schedule(name, spec string, cmd func()) error {
+	id, err := p.cron.AddFunc(spec, cmd)
+	if err != nil {
+		return err
+	}
+	p.rw.Lock()
+	defer p.rw.Unlock()
+	p.entries[id] = &apiEntry{
+		ID:   id,
+		Name: name,
+		Spec: spec,
+	}
+	return nil
+}
+
+// serveAPI 在 unix socket 或回环地址上提供管理接口
+func (p *sProgram) serveAPI() error {
+	var (
+		ln  net.Listener
+		err error
+	)
+	if path, ok := strings.CutPrefix(p.conf.APIListen, "unix://"); ok {
+		// 清理上次异常退出残留的 socket 文件
+		_ = os.Remove(path)
+		ln, err = net.Listen("unix", path)
+		if err == nil {
+			err = os.Chmod(path, 0660)
+		}
+	} else {
+		ln, err = net.Listen("tcp", p.conf.APIListen)
+	}
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/run", p.apiRun)
+	mux.HandleFunc("GET /api/v1/results", p.apiResults)
+	mux.HandleFunc("GET /api/v1/targets", p.apiTargets)
+	mux.HandleFunc("GET /api/v1/schedule", p.apiSchedule)
+	mux.HandleFunc("POST /api/v1/reload", p.apiReload)
+	p.api = &http.Server{
+		Handler: mux,
+	}
+	go func() {
+		if err := p.api.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logx.Errorln(err)
+		}
+	}()
+	return nil
+}
+
+// apiRun 立即执行一次检查, 指定 job 时仅执行该任务, 否则执行所有启用的任务,
+// 已有检查在执行时排队等待; 指定 wait=true 时返回检查的结果
+func (p *sProgram) apiRun(w http.ResponseWriter, r *http.Request) {
+	run := p.runAll
+	if name := r.URL.Query().Get("job"); name != "" {
+		job := p.config().Job(name)
+		if job == nil || !job.IsEnabled() {
+			writeJSON(w, http.StatusNotFound, &apiError{Error: "job not found or disabled: " + name})
+			return
+		}
+		run = func() { p.run(name) }
+	}
+	if r.URL.Query().Get("wait") != "true" {
+		go run()
+		writeJSON(w, http.StatusAccepted, nil)
+		return
+	}
+	run()
+	p.apiResults(w, r)
+}
+
+// apiResults 返回各任务最近一次检查的结果, 指定 job 时仅返回该任务
+func (p *sProgram) apiResults(w http.ResponseWriter, r *http.Request) {
+	var res []*snapshot
+	for _, v := range p.snapshots() {
+		if name := r.URL.Query().Get("job"); name == "" || v.Job == name {
+			res = append(res, v)
+		}
+	}
+	if len(res) == 0 {
+		writeJSON(w, http.StatusNotFound, &apiError{Error: "no check has run yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (p *sProgram) apiTargets(w http.ResponseWriter, _ *http.Request) {
+	var targets []*apiTarget
+	for _, j := range p.config().EnabledJobs() {
+		for _, t := range j.Targets {
+			v := &apiTarget{
+				Job:        j.Name,
+				Path:       t.Path,
+				Suffix:     t.Suffix,
+				Owner:      t.Owner,
+				Team:       t.Team,
+				Labels:     t.Labels,
+				Thresholds: make(map[string]int, len(j.Tiers)),
+			}
+			for _, tier := range j.Tiers {
+				v.Thresholds[tier.Name] = j.Threshold(t, tier)
+			}
+			targets = append(targets, v)
+		}
+	}
+	writeJSON(w, http.StatusOK, targets)
+}
+
+// snapshots 按任务的配置顺序返回各任务最近一次检查的结果
+func (p *sProgram) snapshots() []*snapshot {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+	var res []*snapshot
+	for _, j := range p.conf.Jobs {
+		if v, ok := p.latest[j.Name]; ok {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (p *sProgram) apiSchedule(w http.ResponseWriter, _ *http.Request) {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+	var entries []*apiEntry
+	for _, e := range p.cron.Entries() {
+		v, ok := p.entries[e.ID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, &apiEntry{
+			ID:   e.ID,
+			Name: v.Name,
+			Spec: v.Spec,
+			Next: e.Next,
+			Prev: e.Prev,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Next.Before(entries[j].Next)
+	})
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// apiReload 重新加载配置, 失败时返回错误并继续使用当前配置
+func (p *sProgram) apiReload(w http.ResponseWriter, _ *http.Request) {
+	if err := p.reload(); err != nil {
+		writeJSON(w, http.StatusBadRequest, &apiError{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
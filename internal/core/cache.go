@@ -0,0 +1,96 @@
+package core
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+)
+
+const (
+	// cacheRetention 超过该时间未再检查到的文件从解析缓存中删除
+	cacheRetention = 7 * 24 * time.Hour
+	// cacheRefresh 命中的条目超过该时间才更新检查时间, 避免每次检查都重写整个缓存
+	cacheRefresh = 24 * time.Hour
+)
+
+// sParseCache 一次检查中使用的解析结果缓存, 检查前从状态文件加载, 检查后写回有变化的条目
+type sParseCache struct {
+	mu      sync.Mutex
+	now     time.Time
+	entries map[string]*checker.Cached
+	// 本次检查中新增或需要更新检查时间的条目
+	dirty map[string]bool
+}
+
+func (p *sProgram) loadParseCache() *sParseCache {
+	c := &sParseCache{
+		now:     time.Now(),
+		entries: make(map[string]*checker.Cached),
+		dirty:   make(map[string]bool),
+	}
+	entries, err := p.store.ParseCache()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		return c
+	}
+	for key, data := range entries {
+		var v checker.Cached
+		if err = json.Unmarshal(data, &v); err != nil {
+			continue
+		}
+		c.entries[key] = &v
+	}
+	return c
+}
+
+func (c *sParseCache) Get(key string) (*checker.Cached, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.entries[key]
+	if ok && c.now.Sub(v.SeenAt) >= cacheRefresh {
+		v.SeenAt = c.now
+		c.dirty[key] = true
+	}
+	return v, ok
+}
+
+func (c *sParseCache) Put(key string, v *checker.Cached) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v.SeenAt = c.now
+	c.entries[key] = v
+	c.dirty[key] = true
+}
+
+// saveParseCache 写回有变化的条目, 并删除长时间未检查到的文件
+func (p *sProgram) saveParseCache(c *sParseCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var (
+		entries = make(map[string]json.RawMessage, len(c.dirty))
+		removed []string
+	)
+	for key, v := range c.entries {
+		if c.now.Sub(v.SeenAt) > cacheRetention {
+			removed = append(removed, key)
+			continue
+		}
+		if !c.dirty[key] {
+			continue
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			logx.Errorln(err)
+			continue
+		}
+		entries[key] = data
+	}
+	if err := p.store.SaveParseCache(entries, removed); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+}
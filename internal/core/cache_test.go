@@ -0,0 +1,158 @@
+//go:build unix || windows
+
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// certSize 证书文件补齐到的长度, PEM 块之后的空白在解析时忽略, 以便替换证书而不改变文件大小
+const certSize = 2048
+
+// replaceCert 原地改写证书文件, 补齐到 size 字节并将修改时间设为 mtime, 返回新证书的指纹
+func replaceCert(t *testing.T, p *sProgram, path string, size int, mtime time.Time) string {
+	t.Helper()
+	writeCert(t, path, "a.example.com", time.Now().AddDate(0, 6, 0))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > size {
+		t.Fatalf("certificate of %d bytes exceeds %d", len(data), size)
+	}
+	for len(data) < size {
+		data = append(data, '\n')
+	}
+	// 原地改写, 保持 inode 不变
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = f.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	res, _, err := p.check(p.conf.Job("certs"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res[0].Fingerprint
+}
+
+func checkFingerprint(t *testing.T, p *sProgram) string {
+	t.Helper()
+	res, _, err := p.check(p.conf.Job("certs"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res[0].Fingerprint
+}
+
+func TestParseCache(t *testing.T) {
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name   string
+		verify bool
+		// 首次检查后改写文件的大小与修改时间
+		size  int
+		mtime time.Time
+		// 是否仍返回缓存中的旧结果
+		cached bool
+	}{
+		{"hit", false, certSize, mtime, true},
+		{"mtime changed", false, certSize, mtime.Add(time.Second), false},
+		{"size changed", false, certSize + 1, mtime, false},
+		{"verify detects in-place change", true, certSize, mtime, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+parse_cache: true
+verify_cache: %t
+tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, tt.verify, dir))
+			path := filepath.Join(dir, "a.crt")
+			first := replaceCert(t, p, path, certSize, mtime)
+			if got := checkFingerprint(t, p); got != first {
+				t.Fatalf("first check = %s, want %s", got, first)
+			}
+			second := replaceCert(t, p, path, tt.size, tt.mtime)
+			want := second
+			if tt.cached {
+				want = first
+			}
+			if got := checkFingerprint(t, p); got != want {
+				t.Errorf("fingerprint = %s, want %s (cached %v)", got, want, tt.cached)
+			}
+		})
+	}
+}
+
+// 内容未变化时开启校验仍命中缓存
+func TestParseCacheVerifyHit(t *testing.T) {
+	dir := t.TempDir()
+	p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+parse_cache: true
+verify_cache: true
+tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, dir))
+	path := filepath.Join(dir, "a.crt")
+	want := replaceCert(t, p, path, certSize, time.Now().Add(-time.Hour))
+	for range 2 {
+		if got := checkFingerprint(t, p); got != want {
+			t.Fatalf("fingerprint = %s, want %s", got, want)
+		}
+	}
+	entries, err := p.store.ParseCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache entries = %d, want 1", len(entries))
+	}
+}
+
+// 不使用缓存的检查既不读取也不写入缓存
+func TestCheckWithoutCache(t *testing.T) {
+	dir := t.TempDir()
+	p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+parse_cache: true
+tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, dir))
+	replaceCert(t, p, filepath.Join(dir, "a.crt"), certSize, time.Now())
+	entries, err := p.store.ParseCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("cache entries = %d, want 0", len(entries))
+	}
+}
@@ -0,0 +1,54 @@
+package checker
+
+import (
+	"time"
+)
+
+// ICache 证书文件的解析结果缓存, 由调用方负责持久化, 需要支持并发访问
+type ICache interface {
+	Get(key string) (*Cached, bool)
+	Put(key string, c *Cached)
+}
+
+// Cached 证书文件上一次的解析结果, 剩余天数在使用时根据 NotAfter 重新计算
+type Cached struct {
+	// 文件内容的摘要, 仅在开启校验时记录
+	Sum         string    `json:"sum,omitempty"`
+	DomainName  string    `json:"domain_name"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Serial      string    `json:"serial"`
+	Issuer      string    `json:"issuer"`
+	Fingerprint string    `json:"fingerprint"`
+	// 最近一次检查到该文件的时间, 供调用方清理不再存在的文件
+	SeenAt time.Time `json:"seen_at"`
+}
+
+func newCached(res *Response, sum string) *Cached {
+	return &Cached{
+		Sum:         sum,
+		DomainName:  res.DomainName,
+		NotBefore:   res.NotBefore,
+		NotAfter:    res.NotAfter,
+		Serial:      res.Serial,
+		Issuer:      res.Issuer,
+		Fingerprint: res.Fingerprint,
+	}
+}
+
+func (c *Cached) response(path string) *Response {
+	return &Response{
+		Path:        path,
+		ExpiredDays: expiredDays(c.NotAfter),
+		DomainName:  c.DomainName,
+		NotBefore:   c.NotBefore,
+		NotAfter:    c.NotAfter,
+		Serial:      c.Serial,
+		Issuer:      c.Issuer,
+		Fingerprint: c.Fingerprint,
+	}
+}
+
+func expiredDays(notAfter time.Time) int {
+	return int(notAfter.Sub(time.Now()).Hours() / 24)
+}
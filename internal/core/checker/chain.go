@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Certificate 证书链中单个证书的详细信息
+type Certificate struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	Serial             string    `json:"serial"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	DNSNames           []string  `json:"dns_names,omitempty"`
+	IPAddresses        []string  `json:"ip_addresses,omitempty"`
+	IsCA               bool      `json:"is_ca"`
+	PublicKeyAlgorithm string    `json:"public_key_algorithm"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	Fingerprint        string    `json:"fingerprint"`
+}
+
+// Chain 解析文件中的全部证书, 按文件中的顺序返回
+func Chain(path string) ([]*Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint := sha256.Sum256(cert.Raw)
+		v := &Certificate{
+			Subject:            cert.Subject.String(),
+			Issuer:             cert.Issuer.String(),
+			Serial:             fmt.Sprintf("%X", cert.SerialNumber),
+			NotBefore:          cert.NotBefore,
+			NotAfter:           cert.NotAfter,
+			DNSNames:           cert.DNSNames,
+			IsCA:               cert.IsCA,
+			PublicKeyAlgorithm: cert.PublicKeyAlgorithm.String(),
+			SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+			Fingerprint:        hex.EncodeToString(fingerprint[:]),
+		}
+		for _, ip := range cert.IPAddresses {
+			v.IPAddresses = append(v.IPAddresses, ip.String())
+		}
+		res = append(res, v)
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("decode cert file failed, %s", path)
+	}
+	return res, nil
+}
@@ -1,85 +1,227 @@
 package checker
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultTimeout 单个文件的默认检查超时
+const DefaultTimeout = 30 * time.Second
+
 type IChecker interface {
-	CheckCerts(paths ...string) ([]*Response, error)
+	// SetWorkers 并发解析文件的协程数, 不大于 0 时使用 CPU 核数
+	SetWorkers(n int)
+	// SetTimeout 单个文件的检查超时, 不大于 0 时使用 DefaultTimeout
+	SetTimeout(d time.Duration)
+	// SetCache 文件标识未变化时复用缓存的解析结果, verify 为 true 时还需文件内容的摘要一致
+	SetCache(cache ICache, verify bool)
+	CheckCerts(ctx context.Context, paths ...string) ([]*Response, error)
 }
 
 type sChecker struct {
-	suffix string
-	day    int
+	suffix  string
+	workers int
+	timeout time.Duration
+	cache   ICache
+	verify  bool
 }
 
 type Response struct {
-	Path        string `json:"path"`
-	ExpiredDays int    `json:"expired_days"`
-	DomainName  string `json:"domain_name"`
+	Path        string    `json:"path"`
+	ExpiredDays int       `json:"expired_days"`
+	DomainName  string    `json:"domain_name"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	Serial      string    `json:"serial"`
+	Issuer      string    `json:"issuer"`
+	Fingerprint string    `json:"fingerprint"`
+	// 以下字段由调用方根据检查任务, 目标及告警级别填充
+	Job      string            `json:"job,omitempty"`
+	Owner    string            `json:"owner,omitempty"`
+	Team     string            `json:"team,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Status   string            `json:"status,omitempty"`
+	Silenced bool              `json:"silenced,omitempty"`
+}
+
+// task 待检查的文件, index 为遍历顺序
+type task struct {
+	index int
+	path  string
+}
+
+type result struct {
+	index int
+	res   *Response
+	err   error
 }
 
 func New(suffix string) IChecker {
 	return &sChecker{
-		suffix: suffix,
+		suffix:  suffix,
+		workers: runtime.NumCPU(),
+		timeout: DefaultTimeout,
 	}
 }
 
-func (c *sChecker) CheckCerts(paths ...string) ([]*Response, error) {
-	var res []*Response
-	for _, path := range paths {
-		_res, err := c.checkCert(path)
-		if err != nil {
-			return nil, err
-		}
-		res = append(res, _res...)
+func (c *sChecker) SetWorkers(n int) {
+	if n <= 0 {
+		n = runtime.NumCPU()
 	}
-	return res, nil
+	c.workers = n
+}
+
+func (c *sChecker) SetTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultTimeout
+	}
+	c.timeout = d
 }
 
-func (c *sChecker) checkCert(path string) ([]*Response, error) {
-	var res []*Response
-	// 判断路径是否为文件夹
-	info, err := os.Stat(path)
+func (c *sChecker) SetCache(cache ICache, verify bool) {
+	c.cache = cache
+	c.verify = verify
+}
+
+// CheckCerts 由一个协程依次遍历路径, 多个协程并发解析文件, 结果按遍历顺序返回;
+// 任一文件检查失败或 ctx 取消时停止遍历并返回错误
+func (c *sChecker) CheckCerts(ctx context.Context, paths ...string) ([]*Response, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var (
+		tasks   = make(chan *task, c.workers)
+		results = make(chan *result, c.workers)
+		wg      sync.WaitGroup
+		walkErr error
+	)
+	go func() {
+		defer close(tasks)
+		walkErr = c.produce(ctx, tasks, paths)
+	}()
+	wg.Add(c.workers)
+	for range c.workers {
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				res, err := c.checkFile(ctx, t.path)
+				results <- &result{
+					index: t.index,
+					res:   res,
+					err:   err,
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	var (
+		res []*Response
+		err error
+	)
+	for r := range results {
+		if r.err != nil {
+			// 只保留第一个错误, 其余文件因取消而返回的错误忽略
+			if err == nil {
+				err = r.err
+				cancel(err)
+			}
+			continue
+		}
+		if r.index >= len(res) {
+			res = append(res, make([]*Response, r.index-len(res)+1)...)
+		}
+		res[r.index] = r.res
+	}
+	if err == nil {
+		err = walkErr
+	}
 	if err != nil {
 		return nil, err
 	}
-	if !info.IsDir() {
-		_res, err := c.checkCertByFile(path)
-		if err != nil {
-			return nil, err
+	return res, nil
+}
+
+// produce 依次遍历路径, 为每个待检查的文件按遍历顺序编号
+func (c *sChecker) produce(ctx context.Context, tasks chan<- *task, paths []string) error {
+	var index int
+	send := func(path string) error {
+		select {
+		case tasks <- &task{index: index, path: path}:
+			index++
+			return nil
+		case <-ctx.Done():
+			return context.Cause(ctx)
 		}
-		res = append(res, _res)
-		return res, nil
 	}
-	err = c.WalkPath(path, func(path string, info fs.FileInfo, err error) error {
+	for _, path := range paths {
+		// 判断路径是否为文件夹
+		info, err := os.Stat(path)
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(path, c.suffix) {
-			return nil
+		if !info.IsDir() {
+			if err = send(path); err != nil {
+				return err
+			}
+			continue
 		}
-		_res, err := c.checkCertByFile(path)
+		err = c.WalkPath(path, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			if !strings.HasSuffix(path, c.suffix) {
+				return nil
+			}
+			return send(path)
+		})
 		if err != nil {
 			return err
 		}
-		res = append(res, _res)
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
+	return nil
+}
 
-	return res, nil
+// checkFile 在超时时间内检查单个文件, 超时后不再等待读取结束,
+// 避免网络文件系统上卡住的文件拖住整个检查
+func (c *sChecker) checkFile(parent context.Context, path string) (*Response, error) {
+	if parent.Err() != nil {
+		return nil, context.Cause(parent)
+	}
+	ctx, cancel := context.WithTimeout(parent, c.timeout)
+	defer cancel()
+	done := make(chan *result, 1)
+	go func() {
+		res, err := c.checkCertByFile(path)
+		done <- &result{
+			res: res,
+			err: err,
+		}
+	}()
+	select {
+	case r := <-done:
+		return r.res, r.err
+	case <-ctx.Done():
+		if parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("check cert file timeout after %s, %s", c.timeout, path)
+		}
+		return nil, context.Cause(parent)
+	}
 }
 
 func (c *sChecker) checkCertByFile(path string) (*Response, error) {
@@ -88,10 +230,35 @@ func (c *sChecker) checkCertByFile(path string) (*Response, error) {
 		return nil, fmt.Errorf("file suffix error, %s", path)
 	}
 
+	var (
+		key    string
+		cached *Cached
+		hit    bool
+	)
+	if c.cache != nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if key = fileKey(path, info); key != "" {
+			cached, hit = c.cache.Get(key)
+		}
+	}
+	if hit && !c.verify {
+		return cached.response(path), nil
+	}
 	content, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	var sum string
+	if key != "" && c.verify {
+		s := sha256.Sum256(content)
+		sum = hex.EncodeToString(s[:])
+		if hit && cached.Sum == sum {
+			return cached.response(path), nil
+		}
+	}
 	block, _ := pem.Decode(content)
 	if block == nil {
 		return nil, fmt.Errorf("decode cert file failed, %s", path)
@@ -103,9 +270,19 @@ func (c *sChecker) checkCertByFile(path string) (*Response, error) {
 	if len(cert.DNSNames) == 0 {
 		return nil, fmt.Errorf("cert file dns name is empty, %s", path)
 	}
-	return &Response{
+	fingerprint := sha256.Sum256(cert.Raw)
+	res := &Response{
 		Path:        path,
-		ExpiredDays: int(cert.NotAfter.Sub(time.Now()).Hours() / 24),
+		ExpiredDays: expiredDays(cert.NotAfter),
 		DomainName:  cert.DNSNames[0],
-	}, nil
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		Serial:      fmt.Sprintf("%X", cert.SerialNumber),
+		Issuer:      cert.Issuer.String(),
+		Fingerprint: hex.EncodeToString(fingerprint[:]),
+	}
+	if key != "" {
+		c.cache.Put(key, newCached(res, sum))
+	}
+	return res, nil
 }
@@ -0,0 +1,190 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// writeCert 在 path 写入一个自签名证书, 在 notAfter 过期
+func writeCert(t *testing.T, path, domain string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTree 在多层目录中写入 n 个证书, 并混入其他后缀的文件, 返回根目录
+func writeTree(t *testing.T, n int) string {
+	t.Helper()
+	root := t.TempDir()
+	for i := range n {
+		dir := filepath.Join(root, fmt.Sprintf("d%d", i%4), fmt.Sprintf("s%d", i%3))
+		writeCert(t, filepath.Join(dir, fmt.Sprintf("c%02d.crt", i)), fmt.Sprintf("c%02d.example.com", i), time.Now().AddDate(0, 0, i+1))
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("c%02d.key", i)), []byte("key"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func paths(res []*Response) []string {
+	var names []string
+	for _, v := range res {
+		names = append(names, v.Path)
+	}
+	return names
+}
+
+// 结果按遍历顺序返回, 与并发数无关
+func TestCheckCertsOrder(t *testing.T) {
+	root := writeTree(t, 40)
+	single := filepath.Join(t.TempDir(), "single.crt")
+	writeCert(t, single, "single.example.com", time.Now().AddDate(1, 0, 0))
+
+	var want []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(path) == ".crt" {
+			want = append(want, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, single)
+
+	for _, workers := range []int{1, 2, 8, 64} {
+		c := New(".crt")
+		c.SetWorkers(workers)
+		for range 3 {
+			res, err := c.CheckCerts(context.Background(), root, single)
+			if err != nil {
+				t.Fatalf("workers %d: %v", workers, err)
+			}
+			if got := paths(res); !slices.Equal(got, want) {
+				t.Fatalf("workers %d: order = %v, want %v", workers, got, want)
+			}
+		}
+	}
+}
+
+func TestCheckCertsResponse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.crt")
+	notAfter := time.Now().Add(10*24*time.Hour + time.Hour).Truncate(time.Second)
+	writeCert(t, path, "a.example.com", notAfter)
+	res, err := New(".crt").CheckCerts(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("results = %d", len(res))
+	}
+	v := res[0]
+	if v.DomainName != "a.example.com" || v.ExpiredDays != 10 || !v.NotAfter.Equal(notAfter) {
+		t.Errorf("response = %+v", v)
+	}
+	if len(v.Fingerprint) != 64 || v.Serial == "" {
+		t.Errorf("fingerprint %q serial %q", v.Fingerprint, v.Serial)
+	}
+}
+
+// 任一文件无效或无法读取时返回错误, 不返回部分结果
+func TestCheckCertsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, path string)
+	}{
+		{"not pem", func(t *testing.T, path string) {
+			if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"bad der", func(t *testing.T, path string) {
+			data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"unreadable", func(t *testing.T, path string) {
+			if os.Geteuid() == 0 {
+				t.Skip("root can read files without permission")
+			}
+			writeCert(t, path, "bad.example.com", time.Now().AddDate(1, 0, 0))
+			if err := os.Chmod(path, 0); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeTree(t, 20)
+			tt.setup(t, filepath.Join(root, "d1", "bad.crt"))
+			for _, workers := range []int{1, 8} {
+				c := New(".crt")
+				c.SetWorkers(workers)
+				res, err := c.CheckCerts(context.Background(), root)
+				if err == nil {
+					t.Fatalf("workers %d: expected error, got %d results", workers, len(res))
+				}
+				if res != nil {
+					t.Errorf("workers %d: partial results returned", workers)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckCertsMissingPath(t *testing.T) {
+	_, err := New(".crt").CheckCerts(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("err = %v, want not exist", err)
+	}
+}
+
+func TestCheckCertsCanceled(t *testing.T) {
+	root := writeTree(t, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err := New(".crt").CheckCerts(ctx, root)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context canceled", err)
+	}
+	if res != nil {
+		t.Errorf("results returned after cancel: %d", len(res))
+	}
+}
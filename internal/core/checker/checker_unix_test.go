@@ -0,0 +1,55 @@
+//go:build unix
+
+package checker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// mkfifo 创建一个没有写入方的命名管道, 读取时一直阻塞, 模拟网络文件系统上卡住的文件
+func mkfifo(t *testing.T, path string) {
+	t.Helper()
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+}
+
+func TestCheckCertsTimeout(t *testing.T) {
+	root := writeTree(t, 10)
+	mkfifo(t, filepath.Join(root, "d0", "stuck.crt"))
+	c := New(".crt")
+	c.SetWorkers(4)
+	c.SetTimeout(200 * time.Millisecond)
+	start := time.Now()
+	_, err := c.CheckCerts(context.Background(), root)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s to time out", elapsed)
+	}
+}
+
+// 检查过程中取消时不等待卡住的文件
+func TestCheckCertsCancelWhileBlocked(t *testing.T) {
+	root := writeTree(t, 10)
+	mkfifo(t, filepath.Join(root, "d0", "stuck.crt"))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+	c := New(".crt")
+	c.SetTimeout(time.Minute)
+	start := time.Now()
+	_, err := c.CheckCerts(ctx, root)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s to cancel", elapsed)
+	}
+}
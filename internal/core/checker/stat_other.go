@@ -0,0 +1,12 @@
+//go:build !unix && !windows
+
+package checker
+
+import (
+	"io/fs"
+)
+
+// fileKey 无法获取文件标识的系统上不使用缓存
+func fileKey(_ string, _ fs.FileInfo) string {
+	return ""
+}
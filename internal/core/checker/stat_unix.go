@@ -0,0 +1,18 @@
+//go:build unix
+
+package checker
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// fileKey 由设备号, inode, 大小与修改时间组成缓存键, 文件被替换或修改后键随之变化
+func fileKey(_ string, info fs.FileInfo) string {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d:%d:%d", uint64(st.Dev), uint64(st.Ino), info.Size(), info.ModTime().UnixNano())
+}
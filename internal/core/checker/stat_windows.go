@@ -0,0 +1,29 @@
+//go:build windows
+
+package checker
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// fileKey 由卷序列号, 文件索引, 大小与修改时间组成缓存键, 文件被替换或修改后键随之变化
+func fileKey(path string, info fs.FileInfo) string {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return ""
+	}
+	h, err := syscall.CreateFile(name, 0, syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return ""
+	}
+	defer syscall.CloseHandle(h)
+	var d syscall.ByHandleFileInformation
+	if err = syscall.GetFileInformationByHandle(h, &d); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d:%d:%d", d.VolumeSerialNumber, uint64(d.FileIndexHigh)<<32|uint64(d.FileIndexLow),
+		info.Size(), info.ModTime().UnixNano())
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/pflag"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// newTestProgram 按配置内容创建不启动定时任务的守护进程, 状态文件位于临时目录,
+// 返回的路径为配置文件, 修改后调用 reload 重新加载
+func newTestProgram(t *testing.T, conf string) (*sProgram, string) {
+	t.Helper()
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config.yaml")
+	writeConfig(t, name, conf)
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("config", name, "")
+	flags.String("state_file", filepath.Join(dir, "state.db"), "")
+	flags.String("cron", "0 8 * * 1-5", "")
+	flags.Duration("renotify", 24*time.Hour, "")
+	c, err := config.Load(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := parseTemplates(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &sProgram{
+		flags:     flags,
+		conf:      c,
+		cron:      cron.New(cron.WithParser(parser)),
+		alerts:    newAlerts(c),
+		store:     store.New(c.StateFile),
+		entries:   make(map[cron.EntryID]*apiEntry),
+		latest:    make(map[string]*snapshot),
+		templates: templates,
+		invalid:   make(map[string][sha256.Size]byte),
+		hostname:  "test",
+		lanIP:     "127.0.0.1",
+		wanIP:     "127.0.0.1",
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		p.cancel()
+		if p.unwatch != nil {
+			p.unwatch()
+		}
+	})
+	return p, name
+}
+
+func writeConfig(t *testing.T, name, conf string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(conf), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeCert 在 path 写入一个自签名证书, 在 notAfter 过期
+func writeCert(t *testing.T, path, domain string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
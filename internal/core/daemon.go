@@ -1,8 +1,15 @@
 package core
 
 import (
-	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/kardianos/service"
 	"github.com/robfig/cron/v3"
@@ -10,130 +17,393 @@ import (
 	"github.com/xmapst/logx"
 
 	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/config"
 	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/metrics"
 	"github.com/busybox-org/cert-checker/internal/resolvers"
+	"github.com/busybox-org/cert-checker/internal/store"
 )
 
 type sProgram struct {
 	flags *pflag.FlagSet
+	conf  *config.Config
 	cron  *cron.Cron
-	alert alerter.IAlert
-	check checker.IChecker
-	sHash []byte
-	sURL  string
+	// 告警渠道, key 为渠道名称
+	alerts  map[string]alerter.IAlert
+	store   store.IStore
+	metrics *http.Server
+	api     *http.Server
+	web     *http.Server
+	// 串行执行检查, 发送摘要与重新加载配置, 持有期间 conf 与 alerts 不会被替换
+	mu sync.Mutex
+	// 保护 conf 的替换以及 latest, entries, 供接口与网页读取
+	rw sync.RWMutex
+	// 各任务最近一次检查的结果, key 为任务名称
+	latest map[string]*snapshot
+	// 任务自定义的报告模板, key 为任务名称
+	templates map[string]*template.Template
+	// 已注册的定时任务
+	entries map[cron.EntryID]*apiEntry
+	// 停止时取消, 结束配置与目标路径的监听以及正在进行的检查
+	ctx    context.Context
+	cancel context.CancelFunc
+	// 停止目标路径的监听, 未监听时为空
+	unwatch func()
+	// 已通知的无效证书文件内容的摘要, key 为文件路径
+	invalid map[string][sha256.Size]byte
+	sHash   []byte
+	sURL    string
 	// ecs info
 	hostname string
 	lanIP    string
 	wanIP    string
 }
 
-func New(flags *pflag.FlagSet) service.Interface {
+func New(flags *pflag.FlagSet) (service.Interface, error) {
+	conf, err := config.Load(flags)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := parseTemplates(conf)
+	if err != nil {
+		return nil, err
+	}
 	daemon := &sProgram{
-		flags: flags,
-		alert: alerter.New(flags.Lookup("alert_type").Value.String()),
+		flags:     flags,
+		conf:      conf,
+		entries:   make(map[cron.EntryID]*apiEntry),
+		latest:    make(map[string]*snapshot),
+		templates: templates,
+		invalid:   make(map[string][sha256.Size]byte),
 	}
+	daemon.ctx, daemon.cancel = context.WithCancel(context.Background())
 	daemon.init()
-	return daemon
+	return daemon, nil
 }
+
 func (p *sProgram) init() {
-	p.cron = cron.New(cron.WithParser(cron.NewParser(
-		cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
-	)))
+	p.cron = cron.New(cron.WithParser(parser))
 	p.cron.Start()
 	p.selfUpdate()
+	i18n.SetLang(p.conf.Lang)
 	var err error
 	// 获取主机名
 	p.hostname, err = os.Hostname()
 	if err != nil {
-		logx.Warnf("获取主机名失败: %v", err)
+		logx.Warnln(i18n.T(i18n.LogHostnameFailed, err))
 		p.hostname = "unknown"
 	}
 	// 获取内网IP
 	p.lanIP, err = resolvers.GetInternalIP()
 	if err != nil {
-		logx.Warnf("获取内网ip失败: %v", err)
+		logx.Warnln(i18n.T(i18n.LogLanIPFailed, err))
 		p.lanIP = "unknown"
 	}
 	p.wanIP, err = resolvers.GetExternalIP()
 	if err != nil {
-		logx.Warnf("获取外网ip失败: %v", err)
+		logx.Warnln(i18n.T(i18n.LogWanIPFailed, err))
 		p.wanIP = "unknown"
 	}
 	logx.Debugf("hostname: %s, lan_ip: %s, wan_ip: %s", p.hostname, p.lanIP, p.wanIP)
-	alertType := p.flags.Lookup("alert_type").Value.String()
-	p.alert = alerter.New(alertType)
-	alertAk := p.flags.Lookup("alert_ak").Value.String()
-	p.alert.SetAk(alertAk)
-	alertSK := p.flags.Lookup("alert_sk").Value.String()
-	p.alert.SetSk(alertSK)
+	p.alerts = newAlerts(p.conf)
+	p.store = store.New(store.Path(p.conf.StateFile))
 }
 
 func (p *sProgram) Start(service.Service) error {
-	paths, err := p.flags.GetStringSlice("path")
-	if err != nil {
+	if p.conf.Validate {
+		if err := p.validate(); err != nil {
+			p.cron.Stop()
+			return err
+		}
+	}
+	// 补发上次运行时未送达的消息
+	go func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.flushOutbox()
+	}()
+	if err := p.scheduleJobs(); err != nil {
+		logx.Errorln(err)
 		return err
 	}
-	suffix := p.flags.Lookup("suffix").Value.String()
-	days, err := p.flags.GetInt("days")
+	// 静默时段结束后尽快发送暂存的摘要, 并刷新需要周期推送的事件
+	err := p.schedule("flush", "@every 1m", func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.flush(newBatch("", nil), time.Now())
+		p.keepalive()
+	})
 	if err != nil {
+		logx.Errorln(err)
 		return err
 	}
-	p.check = checker.New(suffix)
-	spec := p.flags.Lookup("cron").Value.String()
-	_, err = p.cron.AddFunc(spec, func() {
-		logx.Infof("开始检查证书...")
-		var res []*checker.Response
-		res, err = p.check.CheckCerts(paths...)
-		if err != nil {
-			logx.Warnf("检查证书失败: %v", err)
-			return
-		}
-		var data = map[string]any{
-			"EcsInfo": map[string]any{
-				"Name":  p.hostname,
-				"LanIp": p.lanIP,
-				"WanIp": p.wanIP,
-			},
-			"ExpireDomain":    []any{},
-			"ThresholdDomain": []any{},
-		}
-		for _, v := range res {
-			if v.ExpiredDays < 0 {
-				data["ExpireDomain"] = append(data["ExpireDomain"].([]any), map[string]any{
-					"Path":        v.Path,
-					"DomainName":  v.DomainName,
-					"ExpiredDays": v.ExpiredDays,
-				})
-				continue
-			}
-			if v.ExpiredDays <= days {
-				data["ThresholdDomain"] = append(data["ThresholdDomain"].([]any), map[string]any{
-					"Path":        v.Path,
-					"DomainName":  v.DomainName,
-					"ExpiredDays": v.ExpiredDays,
-				})
+	if p.conf.MetricsListen != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		p.metrics = &http.Server{
+			Addr:    p.conf.MetricsListen,
+			Handler: mux,
+		}
+		go func() {
+			if err := p.metrics.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logx.Errorln(err)
 			}
+		}()
+	}
+	if p.conf.WebListen != "" {
+		p.serveWeb()
+	}
+	if p.conf.APIListen != "" {
+		if err = p.serveAPI(); err != nil {
+			logx.Errorln(err)
+			return err
+		}
+	}
+	if p.conf.RunOnStart {
+		go p.runAll()
+	}
+	p.mu.Lock()
+	p.watchTargets()
+	p.mu.Unlock()
+	p.watch()
+	return nil
+}
+
+// scheduleJobs 为启用的任务注册定时任务
+func (p *sProgram) scheduleJobs() error {
+	for _, j := range p.conf.EnabledJobs() {
+		name := j.Name
+		if err := p.schedule(jobEntryPrefix+name, j.Cron, func() { p.run(name) }); err != nil {
+			return fmt.Errorf("job %s: %w", name, err)
 		}
-		if len(data["ExpireDomain"].([]any)) <= 0 || len(data["ThresholdDomain"].([]any)) <= 0 {
-			return
+	}
+	return nil
+}
+
+// newAlerts 按配置创建告警渠道, key 为渠道名称
+func newAlerts(conf *config.Config) map[string]alerter.IAlert {
+	var alerts = make(map[string]alerter.IAlert, len(conf.Channels))
+	for _, ch := range conf.Channels {
+		alert := alerter.New(ch.Type)
+		alert.SetUrl(ch.URL)
+		alert.SetAk(ch.AK)
+		alert.SetSk(ch.SK)
+		alert.SetLang(ch.Lang)
+		alerts[ch.Name] = alert
+	}
+	return alerts
+}
+
+// check 检查任务的所有目标并附加目标的归属信息, 返回的 targets 与结果一一对应;
+// cached 为 false 时不读取也不更新解析缓存
+func (p *sProgram) check(job *config.Job, cached bool) (res []*checker.Response, targets []*config.Target, err error) {
+	var cache *sParseCache
+	if cached && p.conf.ParseCache {
+		cache = p.loadParseCache()
+		defer p.saveParseCache(cache)
+	}
+	for _, t := range job.Targets {
+		c := p.newChecker(t)
+		if cache != nil {
+			c.SetCache(cache, p.conf.VerifyCache)
 		}
-		var buf bytes.Buffer
-		defer buf.Reset()
-		if err = tmpl.Execute(&buf, data); err != nil {
-			logx.Errorln(err)
-			return
+		_res, err := c.CheckCerts(p.ctx, t.Path)
+		if err != nil {
+			return nil, nil, err
 		}
-		p.alert.Send(buf.String())
-		logx.Infof("证书检查完成...")
-	})
+		for _, v := range _res {
+			v.Job = job.Name
+			v.Owner = t.Owner
+			v.Team = t.Team
+			v.Labels = t.Labels
+			res = append(res, v)
+			targets = append(targets, t)
+		}
+	}
+	return res, targets, nil
+}
+
+// newChecker 按全局的并发数与单文件超时创建目标的检查器
+func (p *sProgram) newChecker(t *config.Target) checker.IChecker {
+	c := checker.New(t.Suffix)
+	c.SetWorkers(p.conf.Workers)
+	c.SetTimeout(p.conf.FileTimeout)
+	return c
+}
+
+// runAll 依次执行所有启用的任务
+func (p *sProgram) runAll() {
+	for _, j := range p.config().EnabledJobs() {
+		p.run(j.Name)
+	}
+}
+
+// run 执行指定名称的任务, 配置重新加载后任务已被移除或停用时跳过
+func (p *sProgram) run(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	job := p.conf.Job(name)
+	if job == nil || !job.IsEnabled() {
+		return
+	}
+	logx.Infoln(i18n.T(i18n.LogCheckStart, job.Name))
+	var latest = &snapshot{
+		Job:       job.Name,
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		latest.FinishedAt = time.Now()
+		p.rw.Lock()
+		p.latest[job.Name] = latest
+		p.rw.Unlock()
+	}()
+	p.flushOutbox()
+	res, targets, err := p.check(job, true)
 	if err != nil {
-		logx.Errorln(err)
-		return err
+		logx.Warnln(i18n.T(i18n.LogCheckFailed, err))
+		latest.Error = err.Error()
+		return
 	}
-	return nil
+	latest.Results = res
+	p.evaluate(job, res, targets, false)
+	metrics.Set(job.Name, res)
+	logx.Infoln(i18n.T(i18n.LogCheckDone, job.Name))
+}
+
+// evaluate 根据上一次的状态决定需要发送的通知, 并保存状态与变化记录;
+// watched 为文件变化触发的检查, 仅通知新部署的证书
+func (p *sProgram) evaluate(job *config.Job, res []*checker.Response, targets []*config.Target, watched bool) {
+	states, err := p.store.Certs()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		states = map[string]*store.CertState{}
+	}
+	silences, err := p.store.Silences()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+	}
+	var (
+		now      = time.Now()
+		changed  []*store.CertState
+		changes  []*store.Change
+		observed []*store.Observation
+		b        = newBatch(job.Name, p.templates[job.Name])
+	)
+	for i, v := range res {
+		observed = append(observed, &store.Observation{
+			Path:        v.Path,
+			DomainName:  v.DomainName,
+			Fingerprint: v.Fingerprint,
+			Serial:      v.Serial,
+			Issuer:      v.Issuer,
+			NotBefore:   v.NotBefore,
+			NotAfter:    v.NotAfter,
+			FirstSeen:   now,
+			LastSeen:    now,
+		})
+		prev := states[v.Path]
+		state, tier, ev := p.nextState(job, targets[i], prev, v, now)
+		changed = append(changed, state)
+		v.Status = state.Status
+		v.Silenced = silenced(silences, v, now)
+		deployed := watched && (prev == nil || prev.Fingerprint != v.Fingerprint)
+		// 新部署的证书已临近过期时立即告警, 不受重复通知间隔限制;
+		// 文件变化触发的检查只通知新部署的证书, 其余证书的重复告警留给定时检查
+		if deployed && ev == eventNone && tier != nil {
+			ev = eventAlert
+			state.LastNotified = now
+		}
+		// 未重新部署的证书同时保留原状态, 否则期间升级的级别在定时检查时被视为未变化, 直到重复间隔后才告警
+		if watched && !deployed && ev != eventNone {
+			ev = eventNone
+			state.Status = prev.Status
+			state.LastNotified = prev.LastNotified
+		}
+		if c := change(prev, state); c != nil {
+			changes = append(changes, c)
+		}
+		if ev == eventNone {
+			continue
+		}
+		// 静默的证书仍记录状态与指标, 但不发送通知, 静默结束后按原通知时间继续重复告警
+		if v.Silenced {
+			logx.Infoln(i18n.T(i18n.LogSilenced, v.Path, v.DomainName))
+			if prev != nil {
+				state.LastNotified = prev.LastNotified
+			} else {
+				state.LastNotified = time.Time{}
+			}
+			continue
+		}
+		var item = &reportItem{
+			Kind:        kindThreshold,
+			Path:        v.Path,
+			DomainName:  v.DomainName,
+			Tier:        tier.Name,
+			Critical:    tier.Critical,
+			Owner:       v.Owner,
+			Team:        v.Team,
+			Labels:      v.Labels,
+			ExpiredDays: v.ExpiredDays,
+			NotAfter:    v.NotAfter,
+			Serial:      v.Serial,
+			Deployed:    deployed && ev == eventAlert,
+		}
+		switch {
+		case ev == eventRenewed:
+			item.Kind = kindRenewed
+			item.OldNotAfter = prev.NotAfter.Format(time.DateOnly)
+			item.NewNotAfter = v.NotAfter.Format(time.DateOnly)
+			item.OldSerial = prev.Serial
+			item.NewSerial = v.Serial
+		case state.Status == statusExpired:
+			item.Kind = kindExpired
+		}
+		critical := ev == eventAlert && (state.Status == statusExpired || tier.Critical)
+		for _, name := range p.channels(job, v, tier, state, prev, ev) {
+			p.deliver(b, name, item, critical, now)
+		}
+	}
+	p.flush(b, now)
+	p.resolveIncidents(job, res, !watched)
+	if err = p.store.SaveCerts(changed...); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+	if err = p.store.SaveChanges(changes...); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+	if err = p.store.Observe(p.conf.HistoryGap, observed...); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+}
+
+// channels 按路由规则决定结果发送的告警渠道, 续期通知按原告警级别路由,
+// 未配置路由或路由未命中时使用级别上的渠道
+func (p *sProgram) channels(job *config.Job, res *checker.Response, tier *config.Tier, state, prev *store.CertState, ev event) []string {
+	if job.Route == nil {
+		return tier.Channels
+	}
+	severity := state.Status
+	if ev == eventRenewed {
+		severity = prev.Status
+	}
+	if channels := job.Route.Resolve(res, severity); len(channels) > 0 {
+		return channels
+	}
+	return tier.Channels
 }
 
 func (p *sProgram) Stop(service.Service) error {
+	p.cancel()
 	p.cron.Stop()
-	return nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var errs []error
+	for _, srv := range []*http.Server{p.metrics, p.api, p.web} {
+		if srv != nil {
+			errs = append(errs, srv.Shutdown(ctx))
+		}
+	}
+	return errors.Join(errs...)
 }
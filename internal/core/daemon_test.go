@@ -0,0 +1,77 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// 文件变化触发的检查不通知未重新部署的证书, 期间升级的级别仍需在定时检查时告警
+func TestWatchedRecheckKeepsEscalation(t *testing.T) {
+	srv := newPagerDutyServer(t)
+	dir := t.TempDir()
+	p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+channels:
+  - name: pd
+    type: pagerduty
+    ak: key
+    url: %s
+    retries: -1
+tiers:
+  - name: critical
+    days: 3
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, srv.URL, dir))
+	path := filepath.Join(dir, "a.crt")
+	writeCert(t, path, "a.example.com", time.Now().Add(2*24*time.Hour+time.Hour))
+	job := p.conf.Job("certs")
+	res, targets, err := p.check(job, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 上一次检查时处于 warning 级别且刚通知过
+	err = p.store.SaveCerts(&store.CertState{
+		Path:         path,
+		DomainName:   res[0].DomainName,
+		Status:       "warning",
+		NotAfter:     res[0].NotAfter,
+		Serial:       res[0].Serial,
+		Fingerprint:  res[0].Fingerprint,
+		LastNotified: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.evaluate(job, res, targets, true)
+	if got := srv.actions(); len(got) != 0 {
+		t.Fatalf("watched recheck should not alert, got %v", got)
+	}
+	states, err := p.store.Certs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if states[path].Status != "warning" {
+		t.Errorf("watched recheck saved status %s, want warning", states[path].Status)
+	}
+
+	res, targets, err = p.check(job, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.evaluate(job, res, targets, false)
+	if got := srv.actions(); len(got) != 1 || got[0] != "trigger/"+p.incidentKey(path) {
+		t.Fatalf("scheduled run should alert the escalation, got %v", got)
+	}
+	if states, _ = p.store.Certs(); states[path].Status != "critical" {
+		t.Errorf("status = %s, want critical", states[path].Status)
+	}
+}
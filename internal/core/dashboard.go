@@ -0,0 +1,258 @@
+package core
+
+import (
+	"cmp"
+	"embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+//go:embed web/*.html
+var webFS embed.FS
+
+var webTmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"t":       i18n.Translator(i18n.Default),
+	"time":    formatTime,
+	"date":    func(t time.Time) string { return t.Format(time.DateOnly) },
+	"labels":  sortedLabels,
+	"class":   statusClass,
+	"certURL": func(path string) string { return "cert?path=" + url.QueryEscape(path) },
+}).ParseFS(webFS, "web/*.html"))
+
+// 可排序的列
+var columns = []string{"domain", "path", "issuer", "not_after", "status", "owner"}
+
+type column struct {
+	Key   string
+	Title string
+	URL   string
+	// 当前排序方向, 未按该列排序时为空
+	Order string
+}
+
+type listView struct {
+	Latest   []*snapshot
+	Items    []*checker.Response
+	Columns  []*column
+	Statuses []string
+	Query    string
+	Status   string
+	Label    string
+}
+
+type certView struct {
+	Result   *checker.Response
+	Chain    []*checker.Certificate
+	ChainErr string
+	Changes  []*store.Change
+	// 路径上先后部署过的证书
+	Timeline []*store.Observation
+}
+
+// serveWeb 提供只读的证书清单网页
+func (p *sProgram) serveWeb() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", p.webList)
+	mux.HandleFunc("GET /cert", p.webCert)
+	p.web = &http.Server{
+		Addr:    p.conf.WebListen,
+		Handler: mux,
+	}
+	go func() {
+		if err := p.web.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logx.Errorln(err)
+		}
+	}()
+}
+
+// webList 证书列表, 支持按关键字, 状态与标签筛选, 按列排序
+func (p *sProgram) webList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	conf := p.config()
+	view := &listView{
+		Query:    strings.TrimSpace(q.Get("q")),
+		Status:   q.Get("status"),
+		Label:    strings.TrimSpace(q.Get("label")),
+		Statuses: []string{statusOK},
+	}
+	for _, j := range conf.EnabledJobs() {
+		for _, tier := range j.Tiers {
+			if !slices.Contains(view.Statuses, tier.Name) {
+				view.Statuses = append(view.Statuses, tier.Name)
+			}
+		}
+	}
+	view.Statuses = append(view.Statuses, statusExpired)
+	view.Latest = p.snapshots()
+	for _, s := range view.Latest {
+		for _, v := range s.Results {
+			if view.match(v) {
+				view.Items = append(view.Items, v)
+			}
+		}
+	}
+	sortBy, order := q.Get("sort"), q.Get("order")
+	if !slices.Contains(columns, sortBy) {
+		sortBy = "not_after"
+	}
+	if order != "desc" {
+		order = "asc"
+	}
+	slices.SortStableFunc(view.Items, func(a, b *checker.Response) int {
+		c := compare(sortBy, a, b)
+		if order == "desc" {
+			return -c
+		}
+		return c
+	})
+	for _, key := range columns {
+		col := &column{
+			Key:   key,
+			Title: i18n.Tl(conf.Lang, "web."+key),
+		}
+		next := url.Values{}
+		for k, v := range q {
+			next[k] = v
+		}
+		next.Set("sort", key)
+		next.Set("order", "asc")
+		if key == sortBy {
+			col.Order = order
+			if order == "asc" {
+				next.Set("order", "desc")
+			}
+		}
+		col.URL = "?" + next.Encode()
+		view.Columns = append(view.Columns, col)
+	}
+	p.renderWeb(w, http.StatusOK, "list.html", view)
+}
+
+// webCert 证书详情, 仅允许查看最近一次检查结果中的路径
+func (p *sProgram) webCert(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	view := &certView{}
+	for _, s := range p.snapshots() {
+		if i := slices.IndexFunc(s.Results, func(v *checker.Response) bool { return v.Path == path }); i >= 0 {
+			view.Result = s.Results[i]
+			break
+		}
+	}
+	if view.Result == nil {
+		p.renderWeb(w, http.StatusNotFound, "cert.html", view)
+		return
+	}
+	var err error
+	view.Chain, err = checker.Chain(path)
+	if err != nil {
+		view.ChainErr = err.Error()
+	}
+	view.Changes, err = p.store.Changes(path)
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+	}
+	view.Timeline, err = p.store.History(path)
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+	}
+	// 最近的变化与部署在前
+	slices.Reverse(view.Changes)
+	slices.Reverse(view.Timeline)
+	p.renderWeb(w, http.StatusOK, "cert.html", view)
+}
+
+// renderWeb 以 status 渲染页面, 响应头需在写入状态码之前设置
+func (p *sProgram) renderWeb(w http.ResponseWriter, status int, name string, data any) {
+	t, err := webTmpl.Clone()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	t.Funcs(template.FuncMap{
+		"t": i18n.Translator(p.config().Lang),
+	})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err = t.ExecuteTemplate(w, name, data); err != nil {
+		logx.Errorln(err)
+	}
+}
+
+func (v *listView) match(res *checker.Response) bool {
+	if v.Status != "" && res.Status != v.Status {
+		return false
+	}
+	if v.Query != "" {
+		q := strings.ToLower(v.Query)
+		var found bool
+		for _, s := range []string{res.DomainName, res.Path, res.Issuer, res.Owner, res.Team, res.Job} {
+			if strings.Contains(strings.ToLower(s), q) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if v.Label != "" {
+		key, value, ok := strings.Cut(v.Label, "=")
+		actual, exists := res.Labels[strings.TrimSpace(key)]
+		if !exists || (ok && actual != strings.TrimSpace(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func compare(key string, a, b *checker.Response) int {
+	switch key {
+	case "domain":
+		return cmp.Compare(a.DomainName, b.DomainName)
+	case "path":
+		return cmp.Compare(a.Path, b.Path)
+	case "issuer":
+		return cmp.Compare(a.Issuer, b.Issuer)
+	case "status":
+		return cmp.Compare(a.Status, b.Status)
+	case "owner":
+		return cmp.Compare(a.Owner, b.Owner)
+	}
+	return a.NotAfter.Compare(b.NotAfter)
+}
+
+func statusClass(status string) string {
+	switch status {
+	case statusOK:
+		return "ok"
+	case statusExpired:
+		return "expired"
+	}
+	return "warning"
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func sortedLabels(labels map[string]string) []string {
+	var res []string
+	for _, k := range sortedNames(labels) {
+		res = append(res, k+"="+labels[k])
+	}
+	return res
+}
@@ -0,0 +1,260 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// incidentKey 同一主机上同一路径的证书续期前后使用相同的去重键
+func (p *sProgram) incidentKey(path string) string {
+	sum := sha256.Sum256([]byte(p.hostname + ":" + path))
+	return "cert-checker-" + hex.EncodeToString(sum[:16])
+}
+
+// dispatch 按证书逐条创建或恢复事件, 事件记录在状态文件中以便后续恢复, 创建失败的事件由每分钟的任务重新推送
+func (p *sProgram) dispatch(name string, incident alerter.IIncident, r *report) {
+	lang := p.conf.Channel(name).Lang
+	opened, err := p.openIncidents(name)
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+	}
+	for _, item := range r.items() {
+		e := &alerter.Event{
+			Key:         p.incidentKey(item.Path),
+			Source:      p.hostname,
+			Severity:    p.alertSeverity(item),
+			Path:        item.Path,
+			DomainName:  item.DomainName,
+			Status:      item.severity(),
+			ExpiredDays: item.ExpiredDays,
+			NotAfter:    item.NotAfter,
+			Serial:      item.Serial,
+			Owner:       item.Owner,
+			Team:        item.Team,
+			Labels:      item.Labels,
+		}
+		if item.Kind == kindRenewed {
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentResolved, item.DomainName, item.NewNotAfter)
+			p.resolve(name, incident, lastEvent(opened[item.Path], e))
+			continue
+		}
+		switch item.Kind {
+		case kindExpired:
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentExpired, item.DomainName, -item.ExpiredDays, item.Path)
+		case kindInvalid:
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentInvalid, item.Path, item.Error)
+		default:
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentExpiring, item.DomainName, item.ExpiredDays, item.Path)
+		}
+		// 保持事件首次打开的时间
+		e.StartsAt = time.Now()
+		if v, ok := opened[item.Path]; ok {
+			e.StartsAt = v.OpenedAt
+		}
+		ch := p.conf.Channel(name)
+		// 重试后仍失败的事件同样记录下来, 由每分钟的任务继续推送
+		pending := alerter.Retry(ch.Retries, ch.Backoff, func() error {
+			return incident.Trigger(e)
+		})
+		if pending != nil {
+			logx.Errorln(i18n.T(i18n.LogIncidentFailed, name, e.Key, pending))
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			logx.Errorln(err)
+			continue
+		}
+		v := &store.Incident{
+			Channel:    name,
+			Key:        e.Key,
+			Path:       item.Path,
+			DomainName: item.DomainName,
+			OpenedAt:   e.StartsAt,
+			Event:      data,
+			Pending:    pending != nil,
+		}
+		if pending == nil {
+			v.PushedAt = time.Now()
+		}
+		err = p.store.SaveIncident(v)
+		if err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+		}
+	}
+}
+
+func (p *sProgram) openIncidents(name string) (map[string]*store.Incident, error) {
+	incidents, err := p.store.Incidents()
+	if err != nil {
+		return nil, err
+	}
+	var res = make(map[string]*store.Incident)
+	for _, v := range incidents {
+		if v.Channel == name {
+			res[v.Path] = v
+		}
+	}
+	return res, nil
+}
+
+// keepalive 重试创建或恢复失败的事件, 并在需要周期刷新的渠道上距上次推送超过 TTL 一半时
+// 重新推送仍处于打开状态的事件, 每分钟执行一次, 每个事件只尝试一次
+func (p *sProgram) keepalive() {
+	incidents, err := p.store.Incidents()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		return
+	}
+	now := time.Now()
+	for _, v := range incidents {
+		incident, ok := p.alerts[v.Channel].(alerter.IIncident)
+		if !ok || len(v.Event) == 0 {
+			continue
+		}
+		refresh := false
+		if k, ok := incident.(alerter.IKeepalive); ok {
+			refresh = now.Sub(v.PushedAt) >= k.TTL()/2
+		}
+		if !v.Pending && !v.Resolving && !refresh {
+			continue
+		}
+		var e alerter.Event
+		if err = json.Unmarshal(v.Event, &e); err != nil {
+			logx.Errorln(err)
+			continue
+		}
+		if v.Resolving {
+			if err = incident.Resolve(&e); err != nil {
+				logx.Errorln(i18n.T(i18n.LogIncidentFailed, v.Channel, e.Key, err))
+				continue
+			}
+			if err = p.store.DeleteIncident(v.Channel, v.Path); err != nil {
+				logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+			}
+			continue
+		}
+		if err = incident.Trigger(&e); err != nil {
+			logx.Errorln(i18n.T(i18n.LogIncidentFailed, v.Channel, e.Key, err))
+			continue
+		}
+		v.Pending = false
+		v.PushedAt = now
+		if err = p.store.SaveIncident(v); err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+		}
+	}
+}
+
+// resolveIncidents 恢复证书已不再告警的事件, 包括之前恢复失败的事件;
+// complete 为任务的完整检查结果, 此时还恢复任务覆盖但未检查到(文件已删除)
+// 以及不再被任何启用任务覆盖(目标或任务已移除)的证书的事件
+func (p *sProgram) resolveIncidents(job *config.Job, res []*checker.Response, complete bool) {
+	incidents, err := p.store.Incidents()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		return
+	}
+	if len(incidents) == 0 {
+		return
+	}
+	var current = make(map[string]*checker.Response, len(res))
+	for _, v := range res {
+		current[v.Path] = v
+	}
+	for _, v := range incidents {
+		incident, ok := p.alerts[v.Channel].(alerter.IIncident)
+		ch := p.conf.Channel(v.Channel)
+		if !ok || ch == nil {
+			// 渠道已被移除或更换类型, 无法再恢复
+			if err = p.store.DeleteIncident(v.Channel, v.Path); err != nil {
+				logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+			}
+			continue
+		}
+		var (
+			cert, checked = current[v.Path]
+			lang          = ch.Lang
+			e             = &alerter.Event{
+				Key:        v.Key,
+				Source:     p.hostname,
+				Path:       v.Path,
+				DomainName: v.DomainName,
+			}
+		)
+		switch {
+		case checked && cert.Status == statusOK:
+			e.DomainName = cert.DomainName
+			e.Status = cert.Status
+			e.NotAfter = cert.NotAfter
+			e.Serial = cert.Serial
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentResolved, cert.DomainName, cert.NotAfter.Format(time.DateOnly))
+		case !checked && complete && (covers(job, v.Path) != nil || p.unowned(v.Path)):
+			e.Summary = i18n.Tl(lang, i18n.AlertIncidentRemoved, v.Path)
+		default:
+			continue
+		}
+		p.resolve(v.Channel, incident, lastEvent(v, e))
+	}
+}
+
+// unowned 判断文件是否已不被任何启用任务覆盖
+func (p *sProgram) unowned(file string) bool {
+	job, _ := p.owner(file)
+	return job == nil
+}
+
+// lastEvent 恢复时沿用最近一次推送的事件, 以便 Alertmanager 等按标签识别告警的渠道匹配到原告警,
+// 仅更新摘要
+func lastEvent(incident *store.Incident, e *alerter.Event) *alerter.Event {
+	if incident == nil || len(incident.Event) == 0 {
+		return e
+	}
+	var last alerter.Event
+	if err := json.Unmarshal(incident.Event, &last); err != nil {
+		return e
+	}
+	last.Summary = e.Summary
+	return &last
+}
+
+// resolve 恢复事件, 重试后仍失败的事件标记为待恢复, 由每分钟的任务继续恢复
+func (p *sProgram) resolve(name string, incident alerter.IIncident, e *alerter.Event) {
+	ch := p.conf.Channel(name)
+	err := alerter.Retry(ch.Retries, ch.Backoff, func() error {
+		return incident.Resolve(e)
+	})
+	if err == nil {
+		if err = p.store.DeleteIncident(name, e.Path); err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+		}
+		return
+	}
+	logx.Errorln(i18n.T(i18n.LogIncidentFailed, name, e.Key, err))
+	data, err := json.Marshal(e)
+	if err != nil {
+		logx.Errorln(err)
+		return
+	}
+	err = p.store.SaveIncident(&store.Incident{
+		Channel:    name,
+		Key:        e.Key,
+		Path:       e.Path,
+		DomainName: e.DomainName,
+		OpenedAt:   e.StartsAt,
+		Event:      data,
+		Resolving:  true,
+	})
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+}
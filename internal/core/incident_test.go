@@ -0,0 +1,138 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// pagerdutyServer 记录收到的 PagerDuty 事件
+type pagerdutyServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []map[string]any
+}
+
+func newPagerDutyServer(t *testing.T) *pagerdutyServer {
+	t.Helper()
+	s := &pagerdutyServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("unmarshal %q: %v", data, err)
+		}
+		s.mu.Lock()
+		s.events = append(s.events, body)
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, `{"status":"success"}`)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *pagerdutyServer) actions() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var res []string
+	for _, e := range s.events {
+		res = append(res, fmt.Sprintf("%v/%v", e["event_action"], e["dedup_key"]))
+	}
+	return res
+}
+
+func incidentConfig(dir, url string, channels ...string) string {
+	conf := "lang: en\nchannels:\n"
+	for _, name := range channels {
+		conf += fmt.Sprintf("  - name: %s\n    type: pagerduty\n    ak: key\n    url: %s\n    retries: -1\n", name, url)
+	}
+	return conf + fmt.Sprintf(`tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, dir)
+}
+
+func saveIncident(t *testing.T, p *sProgram, channel, path string) {
+	t.Helper()
+	data, _ := json.Marshal(&alerter.Event{Key: channel + ":" + path, Path: path})
+	err := p.store.SaveIncident(&store.Incident{
+		Channel:  channel,
+		Key:      channel + ":" + path,
+		Path:     path,
+		OpenedAt: time.Now(),
+		Event:    data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// 重新加载移除了打开事件的渠道后, 恢复事件时不应访问已不存在的渠道配置
+func TestResolveIncidentsAfterChannelRemoved(t *testing.T) {
+	srv := newPagerDutyServer(t)
+	dir := t.TempDir()
+	p, name := newTestProgram(t, incidentConfig(dir, srv.URL, "pd", "old"))
+	renewed := filepath.Join(dir, "renewed.crt")
+	deleted := filepath.Join(dir, "deleted.crt")
+	writeCert(t, renewed, "renewed.example.com", time.Now().AddDate(1, 0, 0))
+	saveIncident(t, p, "pd", renewed)
+	saveIncident(t, p, "pd", deleted)
+	saveIncident(t, p, "old", renewed)
+	saveIncident(t, p, "old", deleted)
+
+	writeConfig(t, name, incidentConfig(dir, srv.URL, "pd"))
+	if err := p.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if p.conf.Channel("old") != nil {
+		t.Fatal("channel old should be removed by reload")
+	}
+	job := p.conf.Job("certs")
+	res, _, err := p.check(job, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range res {
+		v.Status = statusOK
+	}
+	p.resolveIncidents(job, res, true)
+
+	incidents, err := p.store.Incidents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incidents) != 0 {
+		for _, v := range incidents {
+			t.Errorf("incident left open: %s %s", v.Channel, v.Path)
+		}
+	}
+	// 被移除的渠道上的事件直接删除, 其余渠道逐条恢复
+	want := map[string]bool{
+		"resolve/pd:" + renewed: true,
+		"resolve/pd:" + deleted: true,
+	}
+	got := srv.actions()
+	if len(got) != len(want) {
+		t.Fatalf("events = %v", got)
+	}
+	for _, v := range got {
+		if !want[v] {
+			t.Errorf("unexpected event %s", v)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// send 按渠道的单条消息上限拆分后逐条发送, 提醒仅附加在第一条上, 按渠道配置重试,
+// 仍失败时将该条及后续各条保存到待发送队列, 在下次检查或重启时补发
+func (p *sProgram) send(name string, msg *alerter.Message) {
+	ch := p.conf.Channel(name)
+	var err error
+	for i, chunk := range p.paginate(name, msg.Text) {
+		part := &alerter.Message{
+			Title:    msg.Title,
+			Text:     chunk,
+			Severity: msg.Severity,
+			Buttons:  msg.Buttons,
+			Vertical: msg.Vertical,
+		}
+		if i == 0 {
+			part.AtMobiles = msg.AtMobiles
+			part.AtUserIds = msg.AtUserIds
+			part.AtAll = msg.AtAll
+		}
+		if err == nil {
+			if err = alerter.SendWithRetry(p.alerts[name], part, ch.Retries, ch.Backoff); err == nil {
+				continue
+			}
+			logx.Errorln(i18n.T(i18n.LogSendFailed, name, err))
+		}
+		pending := &store.Message{
+			Channel:   name,
+			Title:     part.Title,
+			Text:      part.Text,
+			Severity:  part.Severity,
+			AtMobiles: part.AtMobiles,
+			AtUserIds: part.AtUserIds,
+			AtAll:     part.AtAll,
+			Attempts:  ch.Retries + 1,
+			LastError: err.Error(),
+			CreatedAt: time.Now(),
+		}
+		if serr := p.store.SaveMessage(pending); serr != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, serr))
+		}
+	}
+}
+
+// paginate 按渠道的单条消息上限拆分报告, 多于一条时添加分页标记,
+// 超出渠道的 MaxMessages 时省略后续部分并附上完整报告链接
+func (p *sProgram) paginate(name, text string) []string {
+	ch := p.conf.Channel(name)
+	limit := p.alerts[name].Limit()
+	if limit <= 0 || len(text) <= limit {
+		return []string{text}
+	}
+	// 预留分页标记与省略提示的空间, 链接过长或渠道上限过小时至少保留一半用于正文,
+	// 否则拆分长度不为正数时不会拆分
+	reserve := 256 + len(ch.ReportURL)
+	chunks := alerter.Split(text, max(limit-reserve, limit/2))
+	if ch.MaxMessages > 0 && len(chunks) > ch.MaxMessages {
+		omitted := len(chunks) - ch.MaxMessages
+		chunks = chunks[:ch.MaxMessages]
+		note := "\n\n" + i18n.Tl(ch.Lang, i18n.AlertTruncated, omitted)
+		if ch.ReportURL != "" {
+			note += "  \n" + i18n.Tl(ch.Lang, i18n.AlertFullReport, ch.ReportURL)
+		}
+		chunks[len(chunks)-1] += note
+	}
+	if len(chunks) > 1 {
+		for i := range chunks {
+			chunks[i] = i18n.Tl(ch.Lang, i18n.AlertPart, i+1, len(chunks)) + "  \n" + chunks[i]
+		}
+	}
+	return chunks
+}
+
+// flushOutbox 按入队顺序补发消息, 某个渠道补发失败后跳过该渠道剩余的消息以保持顺序
+func (p *sProgram) flushOutbox() {
+	msgs, err := p.store.Outbox()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		return
+	}
+	var failed = make(map[string]bool)
+	for _, msg := range msgs {
+		if failed[msg.Channel] {
+			continue
+		}
+		alert, ok := p.alerts[msg.Channel]
+		if !ok {
+			logx.Warnln(i18n.T(i18n.LogOutboxDropped, msg.Channel))
+			if err = p.store.DeleteMessage(msg.ID); err != nil {
+				logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+			}
+			continue
+		}
+		ch := p.conf.Channel(msg.Channel)
+		// 按钮取自当前的渠道配置
+		part := p.newMessage(ch, msg.Text)
+		part.Title = msg.Title
+		part.Severity = msg.Severity
+		part.AtMobiles = msg.AtMobiles
+		part.AtUserIds = msg.AtUserIds
+		part.AtAll = msg.AtAll
+		if err = alerter.SendWithRetry(alert, part, ch.Retries, ch.Backoff); err != nil {
+			failed[msg.Channel] = true
+			msg.Attempts += ch.Retries + 1
+			msg.LastError = err.Error()
+			logx.Errorln(i18n.T(i18n.LogSendFailed, msg.Channel, err))
+			if err = p.store.SaveMessage(msg); err != nil {
+				logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+			}
+			continue
+		}
+		logx.Infoln(i18n.T(i18n.LogOutboxFlushed, msg.Channel))
+		if err = p.store.DeleteMessage(msg.ID); err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+		}
+	}
+}
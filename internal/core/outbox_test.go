@@ -0,0 +1,52 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	var lines []string
+	for i := range 400 {
+		lines = append(lines, fmt.Sprintf("- cert-%03d.example.com expires in %d days", i, i%30))
+	}
+	text := strings.Join(lines, "\n")
+	tests := []struct {
+		name      string
+		reportURL string
+	}{
+		{"short report url", "https://certs.example.com/"},
+		// 预留空间超过渠道上限
+		{"long report url", "https://certs.example.com/?q=" + strings.Repeat("x", 3000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+channels:
+  - name: tg
+    type: telegram
+    ak: token
+    sk: "42"
+    report_url: %s
+tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, tt.reportURL, t.TempDir()))
+			limit := p.alerts["tg"].Limit()
+			chunks := p.paginate("tg", text)
+			if len(chunks) < 2 {
+				t.Fatalf("text of %d bytes was not split by limit %d", len(text), limit)
+			}
+			for i, c := range chunks {
+				if len(c) > limit {
+					t.Errorf("chunk %d has %d bytes, limit %d", i, len(c), limit)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package core
+
+import (
+	"encoding/json"
+	"text/template"
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// batch 一次检查中各渠道待发送的报告与需要暂存的通知
+type batch struct {
+	// 产生通知的任务, 为空时不属于任何任务, 如每分钟合并摘要
+	job     string
+	reports map[string]*report
+	queued  []*store.Digest
+	// 任务自定义的报告模板, 为空时使用内置模板
+	tmpl *template.Template
+}
+
+func newBatch(job string, tmpl *template.Template) *batch {
+	return &batch{
+		job:     job,
+		reports: make(map[string]*report),
+		tmpl:    tmpl,
+	}
+}
+
+// deliver 非严重通知在渠道静默时段或维护窗口内暂存, 窗口结束后合并为摘要发送
+func (p *sProgram) deliver(b *batch, name string, item *reportItem, critical bool, now time.Time) {
+	if !critical && p.conf.Channel(name).Quiet(now) {
+		data, err := json.Marshal(item)
+		if err != nil {
+			logx.Errorln(err)
+			return
+		}
+		b.queued = append(b.queued, &store.Digest{
+			Channel:  name,
+			Job:      b.job,
+			Item:     data,
+			QueuedAt: now,
+		})
+		return
+	}
+	r, ok := b.reports[name]
+	if !ok {
+		r = p.newReport()
+		b.reports[name] = r
+	}
+	r.add(item)
+}
+
+// flush 保存新暂存的通知, 合并已离开静默时段的渠道的暂存通知, 并发送所有报告;
+// 暂存通知按产生它的任务分别合并, 以便使用各任务自定义的报告模板
+func (p *sProgram) flush(b *batch, now time.Time) {
+	if err := p.store.QueueDigests(b.queued...); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+	// 其他任务的摘要, key 依次为任务与渠道
+	var others = make(map[string]map[string]*report)
+	for _, ch := range p.conf.Channels {
+		if ch.Quiet(now) {
+			continue
+		}
+		digests, err := p.store.TakeDigests(ch.Name)
+		if err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+			continue
+		}
+		for _, d := range digests {
+			var item reportItem
+			if err = json.Unmarshal(d.Item, &item); err != nil {
+				logx.Errorln(err)
+				continue
+			}
+			reports := b.reports
+			if d.Job != b.job {
+				if others[d.Job] == nil {
+					others[d.Job] = make(map[string]*report)
+				}
+				reports = others[d.Job]
+			}
+			r, ok := reports[ch.Name]
+			if !ok {
+				r = p.newReport()
+				reports[ch.Name] = r
+			}
+			r.Digest = true
+			r.add(&item)
+		}
+	}
+	p.dropDigests()
+	p.sendReports(b.reports, b.tmpl)
+	for _, job := range sortedNames(others) {
+		p.sendReports(others[job], p.templates[job])
+	}
+}
+
+// sendReports 按渠道发送报告, 事件类渠道逐条创建或恢复事件, 其他渠道使用 tmpl 渲染
+func (p *sProgram) sendReports(reports map[string]*report, tmpl *template.Template) {
+	for _, name := range sortedNames(reports) {
+		if incident, ok := p.alerts[name].(alerter.IIncident); ok {
+			p.dispatch(name, incident, reports[name])
+			continue
+		}
+		text, err := render(tmpl, p.conf.Channel(name).Lang, reports[name])
+		if err != nil {
+			logx.Errorln(err)
+			continue
+		}
+		p.send(name, p.message(name, reports[name], text))
+	}
+}
+
+// dropDigests 丢弃重新加载配置后已被移除的渠道暂存的通知
+func (p *sProgram) dropDigests() {
+	channels, err := p.store.DigestChannels()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		return
+	}
+	for _, name := range channels {
+		if p.conf.Channel(name) != nil {
+			continue
+		}
+		digests, err := p.store.TakeDigests(name)
+		if err != nil {
+			logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+			continue
+		}
+		logx.Warnln(i18n.T(i18n.LogDigestDropped, name, len(digests)))
+	}
+}
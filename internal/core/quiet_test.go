@@ -0,0 +1,48 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// 重新加载移除的渠道上暂存的通知被丢弃, 仍在维护窗口内的渠道继续暂存
+func TestFlushDropsDigestsOfRemovedChannels(t *testing.T) {
+	p, _ := newTestProgram(t, fmt.Sprintf(`lang: en
+channels:
+  - name: kept
+    type: pagerduty
+    ak: key
+    url: http://127.0.0.1:0
+    maintenance:
+      - start: "2000-01-01 00:00"
+        end: "2100-01-01 00:00"
+tiers:
+  - name: warning
+    days: 15
+jobs:
+  - name: certs
+    targets:
+      - path: %s
+`, t.TempDir()))
+	now := time.Now()
+	err := p.store.QueueDigests(
+		&store.Digest{Channel: "kept", Item: []byte(`{"kind":"threshold","path":"/a.crt"}`), QueuedAt: now},
+		&store.Digest{Channel: "removed", Item: []byte(`{"kind":"threshold","path":"/a.crt"}`), QueuedAt: now},
+		&store.Digest{Channel: "removed", Item: []byte(`{"kind":"threshold","path":"/b.crt"}`), QueuedAt: now},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.flush(newBatch("", nil), now)
+	channels, err := p.store.DigestChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(channels, []string{"kept"}) {
+		t.Errorf("channels with held digests = %v, want [kept]", channels)
+	}
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/robfig/cron/v3"
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/metrics"
+)
+
+// jobEntryPrefix 任务定时任务的名称前缀, 重新加载时按前缀移除
+const jobEntryPrefix = "check/"
+
+// reloadDelay 配置文件变化后等待写入完成再重新加载
+const reloadDelay = time.Second
+
+var parser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// config 返回当前配置, 供不持有 mu 的接口与网页使用
+func (p *sProgram) config() *config.Config {
+	p.rw.RLock()
+	defer p.rw.RUnlock()
+	return p.conf
+}
+
+// reload 重新加载配置, 校验通过后原子地替换任务, 告警渠道与模板并重新注册定时任务,
+// 状态文件及其中的告警记录保持不变; 失败时继续使用当前配置
+func (p *sProgram) reload() error {
+	conf, err := config.Load(p.flags)
+	if err == nil {
+		err = checkCron(conf)
+	}
+	var templates map[string]*template.Template
+	if err == nil {
+		templates, err = parseTemplates(conf)
+	}
+	if err != nil {
+		logx.Errorln(i18n.T(i18n.LogReloadFailed, err))
+		return err
+	}
+	alerts := newAlerts(conf)
+
+	// 等待正在执行的检查结束
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rw.Lock()
+	old := p.conf
+	p.conf, p.alerts, p.templates = conf, alerts, templates
+	for id, e := range p.entries {
+		if strings.HasPrefix(e.Name, jobEntryPrefix) {
+			p.cron.Remove(id)
+			delete(p.entries, id)
+		}
+	}
+	for name := range p.latest {
+		if j := conf.Job(name); j == nil || !j.IsEnabled() {
+			delete(p.latest, name)
+			metrics.Remove(name)
+		}
+	}
+	p.rw.Unlock()
+	if err = p.scheduleJobs(); err != nil {
+		// 规则已预先校验, 不应出现
+		logx.Errorln(i18n.T(i18n.LogReloadFailed, err))
+	}
+	p.watchTargets()
+	i18n.SetLang(conf.Lang)
+	if old.StateFile != conf.StateFile || old.MetricsListen != conf.MetricsListen ||
+		old.APIListen != conf.APIListen || old.WebListen != conf.WebListen {
+		logx.Warnln(i18n.T(i18n.LogReloadRestart))
+	}
+	logx.Infoln(i18n.T(i18n.LogReloaded, len(conf.EnabledJobs())))
+	return nil
+}
+
+// checkCron 校验启用任务的定时规则
+func checkCron(conf *config.Config) error {
+	for _, j := range conf.EnabledJobs() {
+		if _, err := parser.Parse(j.Cron); err != nil {
+			return fmt.Errorf("job %s: %w", j.Name, err)
+		}
+	}
+	return nil
+}
+
+// watch 收到 SIGHUP 或配置文件内容变化时重新加载配置
+func (p *sProgram) watch() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	var (
+		name, _ = p.flags.GetString("config")
+		enable  = name != ""
+		events  <-chan fsnotify.Event
+		errs    <-chan error
+		watcher *fsnotify.Watcher
+		// 最近一次加载的配置文件内容的摘要
+		loaded [sha256.Size]byte
+	)
+	if on, err := p.flags.GetBool("watch_config"); err == nil && !on {
+		enable = false
+	}
+	if enable {
+		name, _ = filepath.Abs(name)
+		loaded, _ = fileSum(name)
+		var err error
+		// 监听所在目录, 编辑器与配置管理工具通常以替换文件的方式保存
+		if watcher, err = fsnotify.NewWatcher(); err == nil {
+			if err = watcher.Add(filepath.Dir(name)); err == nil {
+				events, errs = watcher.Events, watcher.Errors
+			}
+		}
+		if err != nil {
+			logx.Warnln(i18n.T(i18n.LogWatchFailed, name, err))
+		}
+	}
+	go func() {
+		defer signal.Stop(sig)
+		if watcher != nil {
+			defer watcher.Close()
+		}
+		var (
+			timer   = time.NewTimer(reloadDelay)
+			pending <-chan time.Time
+		)
+		timer.Stop()
+		for {
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-sig:
+				logx.Infoln(i18n.T(i18n.LogReloading, "SIGHUP"))
+				if err := p.reload(); err == nil {
+					loaded, _ = fileSum(name)
+				}
+			case ev := <-events:
+				if ev.Op == fsnotify.Chmod {
+					continue
+				}
+				timer.Reset(reloadDelay)
+				pending = timer.C
+			case <-pending:
+				pending = nil
+				// 内容未变化时不重新加载, 如保存了相同的内容或目录中其他文件的变化
+				sum, err := fileSum(name)
+				if err != nil || sum == loaded {
+					continue
+				}
+				logx.Infoln(i18n.T(i18n.LogReloading, name))
+				loaded = sum
+				_ = p.reload()
+			case err := <-errs:
+				logx.Warnln(i18n.T(i18n.LogWatchFailed, name, err))
+			}
+		}
+	}()
+}
+
+func fileSum(name string) ([sha256.Size]byte, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(content), nil
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/config"
+)
+
+const (
+	kindThreshold = "threshold"
+	kindExpired   = "expired"
+	kindRenewed   = "renewed"
+	// 新部署的文件无法解析为有效的证书
+	kindInvalid = "invalid"
+)
+
+// report 单个告警渠道的模板数据
+type report struct {
+	EcsInfo         ecsInfo
+	ExpireDomain    []*reportItem
+	ThresholdDomain []*reportItem
+	RenewedDomain   []*reportItem
+	InvalidDomain   []*reportItem
+	// 是否为静默时段结束后合并发送的摘要
+	Digest bool
+}
+
+type ecsInfo struct {
+	Name  string
+	LanIp string
+	WanIp string
+}
+
+type reportItem struct {
+	Kind        string            `json:"kind"`
+	Path        string            `json:"path"`
+	DomainName  string            `json:"domain_name"`
+	Tier        string            `json:"tier"`
+	Critical    bool              `json:"critical,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Team        string            `json:"team,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ExpiredDays int               `json:"expired_days"`
+	NotAfter    time.Time         `json:"not_after"`
+	Serial      string            `json:"serial"`
+	OldNotAfter string            `json:"old_not_after,omitempty"`
+	NewNotAfter string            `json:"new_not_after,omitempty"`
+	OldSerial   string            `json:"old_serial,omitempty"`
+	NewSerial   string            `json:"new_serial,omitempty"`
+	// 文件变化后检查到的新部署的证书
+	Deployed bool `json:"deployed,omitempty"`
+	// 无效证书的解析错误
+	Error string `json:"error,omitempty"`
+}
+
+func (p *sProgram) newReport() *report {
+	return &report{
+		EcsInfo: ecsInfo{
+			Name:  p.hostname,
+			LanIp: p.lanIP,
+			WanIp: p.wanIP,
+		},
+	}
+}
+
+func (r *report) add(item *reportItem) {
+	switch item.Kind {
+	case kindExpired:
+		r.ExpireDomain = append(r.ExpireDomain, item)
+	case kindRenewed:
+		r.RenewedDomain = append(r.RenewedDomain, item)
+	case kindInvalid:
+		r.InvalidDomain = append(r.InvalidDomain, item)
+	default:
+		r.ThresholdDomain = append(r.ThresholdDomain, item)
+	}
+}
+
+// severity 返回条目的告警级别, 续期通知没有级别, 无效证书按已过期处理
+func (i *reportItem) severity() string {
+	switch i.Kind {
+	case kindExpired, kindInvalid:
+		return statusExpired
+	case kindRenewed:
+		return ""
+	}
+	return i.Tier
+}
+
+// alertSeverity 已过期, 无效或命中严重级别时为 critical, 续期为 info, 其余为 warning
+func (p *sProgram) alertSeverity(item *reportItem) string {
+	switch item.Kind {
+	case kindRenewed:
+		return alerter.SeverityInfo
+	case kindExpired, kindInvalid:
+		return alerter.SeverityCritical
+	}
+	if item.Critical {
+		return alerter.SeverityCritical
+	}
+	return alerter.SeverityWarning
+}
+
+func (r *report) items() []*reportItem {
+	return slices.Concat(r.InvalidDomain, r.ExpireDomain, r.ThresholdDomain, r.RenewedDomain)
+}
+
+func (p *sProgram) newMessage(ch *config.Channel, text string) *alerter.Message {
+	msg := &alerter.Message{
+		Text: text,
+	}
+	if ch.ActionCard != nil {
+		msg.Vertical = ch.ActionCard.Vertical
+		for _, b := range ch.ActionCard.Buttons {
+			msg.Buttons = append(msg.Buttons, &alerter.Button{
+				Title: b.Title,
+				URL:   b.URL,
+			})
+		}
+	}
+	return msg
+}
+
+// message 根据渠道配置为报告附加负责人提醒与跳转按钮
+func (p *sProgram) message(name string, r *report, text string) *alerter.Message {
+	ch := p.conf.Channel(name)
+	msg := p.newMessage(ch, text)
+	msg.Severity = alerter.SeverityInfo
+	for _, item := range r.items() {
+		switch p.alertSeverity(item) {
+		case alerter.SeverityCritical:
+			msg.Severity = alerter.SeverityCritical
+		case alerter.SeverityWarning:
+			if msg.Severity == alerter.SeverityInfo {
+				msg.Severity = alerter.SeverityWarning
+			}
+		}
+		if slices.Contains(ch.AtAll, item.severity()) {
+			msg.AtAll = true
+		}
+		if !ch.MentionOwners || item.Owner == "" {
+			continue
+		}
+		owner, ok := p.conf.Owners[item.Owner]
+		if !ok {
+			continue
+		}
+		if owner.Mobile != "" && !slices.Contains(msg.AtMobiles, owner.Mobile) {
+			msg.AtMobiles = append(msg.AtMobiles, owner.Mobile)
+		}
+		if owner.UserID != "" && !slices.Contains(msg.AtUserIds, owner.UserID) {
+			msg.AtUserIds = append(msg.AtUserIds, owner.UserID)
+		}
+	}
+	return msg
+}
+
+// sortedNames 按名称顺序发送, 保证多渠道的发送顺序稳定
+func sortedNames[T any](m map[string]T) []string {
+	var names = make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
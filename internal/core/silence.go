@@ -0,0 +1,29 @@
+package core
+
+import (
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/glob"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// silenced 判断结果是否命中任一生效中的静默规则
+func silenced(silences []*store.Silence, res *checker.Response, now time.Time) bool {
+	for _, s := range silences {
+		if !s.Active(now) {
+			continue
+		}
+		if s.Fingerprint != "" && s.Fingerprint != res.Fingerprint {
+			continue
+		}
+		if s.Domain != "" && !glob.Match(s.Domain, res.DomainName) {
+			continue
+		}
+		if s.Path != "" && !glob.Match(s.Path, res.Path) {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// 除 statusOK 与 statusExpired 外, 状态取值为命中的告警级别名称
+const (
+	statusOK      = "ok"
+	statusExpired = "expired"
+)
+
+// repeatSlack 判断重复通知间隔时容许的检查时间抖动, 避免定时检查比上次略早执行时
+// 差几毫秒未满间隔而推迟到下一次检查才重复告警
+const repeatSlack = time.Minute
+
+type event int
+
+const (
+	eventNone event = iota
+	// 需要发送过期告警
+	eventAlert
+	// 告警中的证书已被续期
+	eventRenewed
+)
+
+// tierOf 返回状态对应的告警级别, 配置变更后找不到原级别时退回到最宽松的级别
+func tierOf(job *config.Job, status string) *config.Tier {
+	if status == statusExpired {
+		return job.Tiers[0]
+	}
+	if t := job.Tier(status); t != nil {
+		return t
+	}
+	return job.Tiers[len(job.Tiers)-1]
+}
+
+// nextState 根据上一次的状态计算本次状态, 仅在状态变化或超过所在级别的重复通知间隔时
+// 需要发送告警, 之前处于告警状态的证书换成了新的有效期则视为已续期
+func (p *sProgram) nextState(job *config.Job, target *config.Target, prev *store.CertState, res *checker.Response, now time.Time) (*store.CertState, *config.Tier, event) {
+	state := &store.CertState{
+		Path:        res.Path,
+		DomainName:  res.DomainName,
+		Status:      statusOK,
+		ExpiredDays: res.ExpiredDays,
+		NotAfter:    res.NotAfter,
+		Serial:      res.Serial,
+		Fingerprint: res.Fingerprint,
+		UpdatedAt:   now,
+	}
+	if prev != nil {
+		state.LastNotified = prev.LastNotified
+	}
+	tier := job.Classify(target, res.ExpiredDays)
+	if tier == nil {
+		if prev != nil && prev.Status != statusOK && !prev.NotAfter.Equal(res.NotAfter) {
+			state.LastNotified = now
+			return state, tierOf(job, prev.Status), eventRenewed
+		}
+		return state, nil, eventNone
+	}
+	state.Status = tier.Name
+	if res.ExpiredDays < 0 {
+		state.Status = statusExpired
+	}
+	if prev == nil || prev.Status != state.Status || now.Add(repeatSlack).Sub(prev.LastNotified) >= tier.Repeat {
+		state.LastNotified = now
+		return state, tier, eventAlert
+	}
+	return state, tier, eventNone
+}
+
+// change 证书首次发现, 状态变化或文件被替换为其他证书时返回变化记录
+func change(prev, state *store.CertState) *store.Change {
+	if prev != nil && prev.Status == state.Status && prev.Fingerprint == state.Fingerprint {
+		return nil
+	}
+	c := &store.Change{
+		Path:        state.Path,
+		DomainName:  state.DomainName,
+		Status:      state.Status,
+		ExpiredDays: state.ExpiredDays,
+		NotAfter:    state.NotAfter,
+		Serial:      state.Serial,
+		Fingerprint: state.Fingerprint,
+		Time:        state.UpdatedAt,
+	}
+	if prev != nil {
+		c.PrevStatus = prev.Status
+	}
+	return c
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+func TestNextState(t *testing.T) {
+	var (
+		p      = &sProgram{}
+		target = &config.Target{Path: "/etc/ssl/a.crt"}
+		job    = &config.Job{
+			Tiers: []*config.Tier{
+				{Name: "critical", Days: 3, Repeat: 24 * time.Hour},
+				{Name: "warning", Days: 15, Repeat: 24 * time.Hour},
+			},
+		}
+		now      = time.Date(2026, 10, 19, 8, 0, 0, 0, time.Local)
+		notAfter = now.AddDate(0, 0, 10)
+	)
+	warning := func(lastNotified time.Time) *store.CertState {
+		return &store.CertState{Path: target.Path, Status: "warning", NotAfter: notAfter, LastNotified: lastNotified}
+	}
+	tests := []struct {
+		name        string
+		prev        *store.CertState
+		expiredDays int
+		notAfter    time.Time
+		want        event
+		wantStatus  string
+	}{
+		{"first seen", nil, 10, notAfter, eventAlert, "warning"},
+		{"healthy", nil, 30, notAfter, eventNone, statusOK},
+		{"within repeat", warning(now.Add(-12 * time.Hour)), 10, notAfter, eventNone, "warning"},
+		{"repeat elapsed", warning(now.Add(-25 * time.Hour)), 10, notAfter, eventAlert, "warning"},
+		// 定时检查比前一天略早执行
+		{"repeat with jitter", warning(now.Add(-24*time.Hour + 100*time.Millisecond)), 10, notAfter, eventAlert, "warning"},
+		{"repeat with jitter beyond slack", warning(now.Add(-24*time.Hour + 2*time.Minute)), 10, notAfter, eventNone, "warning"},
+		{"escalated", warning(now.Add(-time.Hour)), 2, notAfter, eventAlert, "critical"},
+		{"expired", warning(now.Add(-time.Hour)), -1, notAfter, eventAlert, statusExpired},
+		{"renewed", warning(now.Add(-time.Hour)), 90, notAfter.AddDate(0, 3, 0), eventRenewed, statusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &checker.Response{Path: target.Path, ExpiredDays: tt.expiredDays, NotAfter: tt.notAfter}
+			state, _, ev := p.nextState(job, target, tt.prev, res, now)
+			if ev != tt.want {
+				t.Errorf("event = %d, want %d", ev, tt.want)
+			}
+			if state.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", state.Status, tt.wantStatus)
+			}
+			if ev != eventNone && !state.LastNotified.Equal(now) {
+				t.Errorf("last notified = %s, want %s", state.LastNotified, now)
+			}
+		})
+	}
+}
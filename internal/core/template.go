@@ -1,26 +1,88 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
 	"text/template"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/i18n"
 )
 
-var Template = `###  **主机名**: {{ .EcsInfo.Name }}  
-###  **内网IP**:  {{ .EcsInfo.LanIp }}  
-###  **外网IP**:  {{ .EcsInfo.WanIp }}  
+var Template = `###  **{{ t "alert.hostname" }}**: {{ .EcsInfo.Name }}  
+###  **{{ t "alert.lan_ip" }}**:  {{ .EcsInfo.LanIp }}  
+###  **{{ t "alert.wan_ip" }}**:  {{ .EcsInfo.WanIp }}  
+{{ if .Digest }}> {{ t "alert.digest_hint" }}  
+{{ end -}}
 {{ if not .ThresholdDomain }}{{ else }}
 ___________________________  
-#### **触发告警阈值域名**:  
+#### **{{ t "alert.threshold_title" }}**:  
 {{ range $val := .ThresholdDomain -}}  
-- {{ $val.DomainName }}  还有 <font color=FF0000> {{ $val.ExpiredDays }} </font> 天过期  
+- {{ $val.DomainName }}  {{ t "alert.expires_in" $val.ExpiredDays }}{{ if $val.Deployed }}  {{ t "alert.deployed" }}{{ end }}{{ with $val.Owner }}  @{{ . }}{{ end }}  
 {{ end -}}  
-##### 上述域名请提前更换证书{{ end }}  
+##### {{ t "alert.threshold_hint" }}{{ end }}  
 {{ if not .ExpireDomain }}
 {{ else }}  
 ___________________________  
-#### **失效域名**:  
-{{ range $val := .ExpireDomain -}}> **{{ $val.DomainName }}**
+#### **{{ t "alert.expired_title" }}**:  
+{{ range $val := .ExpireDomain -}}> **{{ $val.DomainName }}**{{ if $val.Deployed }}  {{ t "alert.deployed" }}{{ end }}
+{{ end -}}  
+> ##### <font color=FF0000> {{ t "alert.expired_hint" }}  </font> {{ end }} 
+{{ if not .InvalidDomain }}{{ else }}
+___________________________  
+#### **{{ t "alert.invalid_title" }}**:  
+{{ range $val := .InvalidDomain -}}  
+- {{ $val.Path }}  {{ $val.Error }}{{ with $val.Owner }}  @{{ . }}{{ end }}  
 {{ end -}}  
-> ##### <font color=FF0000> 上述域名已经过期，请确认并进行后续处理  </font> {{ end }} 
+##### {{ t "alert.invalid_hint" }}{{ end }}  
+{{ if not .RenewedDomain }}{{ else }}
+___________________________  
+#### **{{ t "alert.renewed_title" }}**:  
+{{ range $val := .RenewedDomain -}}  
+- {{ $val.DomainName }}  {{ t "alert.renewed_item" $val.OldNotAfter $val.NewNotAfter $val.OldSerial $val.NewSerial }}  
+{{ end -}}{{ end }}
 `
 
-var tmpl = template.Must(template.New("").Parse(Template))
+var tmpl = template.Must(parseTemplate(Template))
+
+// parseTemplate 解析报告模板, 模板中可使用 t 函数翻译文本
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("").Funcs(template.FuncMap{
+		"t": i18n.Translator(i18n.Default),
+	}).Parse(text)
+}
+
+// parseTemplates 解析任务自定义的报告模板, key 为任务名称
+func parseTemplates(conf *config.Config) (map[string]*template.Template, error) {
+	var res = make(map[string]*template.Template)
+	for _, j := range conf.Jobs {
+		if j.Template == "" {
+			continue
+		}
+		t, err := parseTemplate(j.Template)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: template: %w", j.Name, err)
+		}
+		res[j.Name] = t
+	}
+	return res, nil
+}
+
+// render 按告警渠道的语言渲染模板, base 为空时使用内置模板
+func render(base *template.Template, lang string, data any) (string, error) {
+	if base == nil {
+		base = tmpl
+	}
+	t, err := base.Clone()
+	if err != nil {
+		return "", err
+	}
+	t.Funcs(template.FuncMap{
+		"t": i18n.Translator(lang),
+	})
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
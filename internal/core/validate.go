@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/alerter"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+)
+
+// validate 执行一次检查并向所有渠道发送测试消息, 不修改状态文件, 任一步骤失败时返回错误;
+// 检查时不使用解析缓存, 确保所有证书文件都能重新解析
+func (p *sProgram) validate() error {
+	logx.Infoln(i18n.T(i18n.LogValidateStart))
+	var errs []error
+	var total int
+	for _, j := range p.conf.EnabledJobs() {
+		res, _, err := p.check(j, false)
+		if err != nil {
+			logx.Errorln(i18n.T(i18n.LogCheckFailed, err))
+			errs = append(errs, fmt.Errorf("job %s: %w", j.Name, err))
+			continue
+		}
+		logx.Infoln(i18n.T(i18n.LogValidateChecked, j.Name, len(res)))
+		total += len(res)
+	}
+	for _, name := range sortedNames(p.alerts) {
+		if err := p.sendTest(name, total); err != nil {
+			logx.Errorln(i18n.T(i18n.LogValidateSendFailed, name, err))
+			errs = append(errs, fmt.Errorf("channel %s: %w", name, err))
+			continue
+		}
+		logx.Infoln(i18n.T(i18n.LogValidateSent, name))
+	}
+	if err := errors.Join(errs...); err != nil {
+		logx.Errorln(i18n.T(i18n.LogValidateFailed, len(errs)))
+		return err
+	}
+	logx.Infoln(i18n.T(i18n.LogValidatePassed))
+	return nil
+}
+
+// validateKey 测试事件的去重键, 每台主机固定, 多次启动不会产生不同的事件
+func (p *sProgram) validateKey() string {
+	return p.incidentKey("\x00validate")
+}
+
+// sendTest 向渠道发送测试消息; 事件类渠道以固定的去重键创建测试事件后立即恢复,
+// 避免每次启动都留下无法恢复的事件
+func (p *sProgram) sendTest(name string, total int) error {
+	ch := p.conf.Channel(name)
+	if incident, ok := p.alerts[name].(alerter.IIncident); ok {
+		e := &alerter.Event{
+			Key:      p.validateKey(),
+			Summary:  i18n.Tl(ch.Lang, i18n.AlertTestTitle),
+			Severity: alerter.SeverityInfo,
+			Source:   p.hostname,
+			Status:   statusOK,
+			StartsAt: time.Now(),
+		}
+		err := alerter.Retry(ch.Retries, ch.Backoff, func() error {
+			return incident.Trigger(e)
+		})
+		if err != nil {
+			return err
+		}
+		return alerter.Retry(ch.Retries, ch.Backoff, func() error {
+			return incident.Resolve(e)
+		})
+	}
+	msg := p.newMessage(ch, i18n.Tl(ch.Lang, i18n.AlertTestText, p.hostname, p.lanIP, p.wanIP, total))
+	msg.Title = i18n.Tl(ch.Lang, i18n.AlertTestTitle)
+	msg.Severity = alerter.SeverityInfo
+	return alerter.SendWithRetry(p.alerts[name], msg, ch.Retries, ch.Backoff)
+}
@@ -0,0 +1,26 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 事件类渠道的测试事件使用固定的去重键并立即恢复
+func TestValidateResolvesTestIncident(t *testing.T) {
+	srv := newPagerDutyServer(t)
+	dir := t.TempDir()
+	writeCert(t, filepath.Join(dir, "a.crt"), "a.example.com", time.Now().AddDate(1, 0, 0))
+	p, _ := newTestProgram(t, incidentConfig(dir, srv.URL, "pd"))
+	for range 2 {
+		if err := p.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := p.validateKey()
+	want := []string{"trigger/" + key, "resolve/" + key, "trigger/" + key, "resolve/" + key}
+	if got := srv.actions(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
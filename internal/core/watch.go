@@ -0,0 +1,384 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/xmapst/logx"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+	"github.com/busybox-org/cert-checker/internal/i18n"
+	"github.com/busybox-org/cert-checker/internal/metrics"
+	"github.com/busybox-org/cert-checker/internal/store"
+)
+
+// recheckDelay 证书文件变化后等待写入完成再重新检查, 证书与私钥通常先后写入
+const recheckDelay = 2 * time.Second
+
+// statusInvalid 变化记录中新部署的文件无法解析为证书时的状态
+const statusInvalid = "invalid"
+
+// sTargetWatcher 监听目标路径所在的目录, 目录目标递归监听其子目录
+type sTargetWatcher struct {
+	watcher *fsnotify.Watcher
+	// 目录目标, 其下新建的子目录需要加入监听
+	roots []string
+	// 已监听的目录, key 为解析符号链接后的路径, 避免链接成环
+	watched map[string]bool
+	stop    chan struct{}
+}
+
+// watchTargets 监听启用任务的目标路径, 先停止之前的监听, 调用方需持有 mu
+func (p *sProgram) watchTargets() {
+	if p.unwatch != nil {
+		p.unwatch()
+		p.unwatch = nil
+	}
+	if !p.conf.Watch {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogWatchFailed, "targets", err))
+		return
+	}
+	w := &sTargetWatcher{
+		watcher: watcher,
+		watched: make(map[string]bool),
+		stop:    make(chan struct{}),
+	}
+	for _, j := range p.conf.EnabledJobs() {
+		for _, t := range j.Targets {
+			info, err := os.Stat(t.Path)
+			if err != nil {
+				logx.Warnln(i18n.T(i18n.LogWatchFailed, t.Path, err))
+				continue
+			}
+			// 文件目标监听所在目录, 证书通常以替换文件或符号链接的方式更新
+			if !info.IsDir() {
+				w.add(filepath.Dir(t.Path))
+				continue
+			}
+			w.roots = append(w.roots, filepath.Clean(t.Path))
+			w.addTree(t.Path)
+		}
+	}
+	logx.Infoln(i18n.T(i18n.LogWatchTargets, len(w.watched)))
+	p.unwatch = func() { close(w.stop) }
+	go p.watchLoop(w)
+}
+
+func (w *sTargetWatcher) add(dir string) {
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogWatchFailed, dir, err))
+		return
+	}
+	if w.watched[real] {
+		return
+	}
+	if err = w.watcher.Add(dir); err != nil {
+		logx.Warnln(i18n.T(i18n.LogWatchFailed, dir, err))
+		return
+	}
+	w.watched[real] = true
+}
+
+// addTree 监听目录及其子目录, 与检查时一样跟随指向目录的符号链接
+func (w *sTargetWatcher) addTree(root string) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logx.Warnln(i18n.T(i18n.LogWatchFailed, path, err))
+			return nil
+		}
+		if d.IsDir() {
+			w.add(path)
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				if real, err := filepath.EvalSymlinks(path); err == nil && !w.watched[real] {
+					w.addTree(path)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// covered 判断新建的目录是否位于目录目标之下
+func (w *sTargetWatcher) covered(dir string) bool {
+	return slices.ContainsFunc(w.roots, func(root string) bool {
+		return within(root, dir)
+	})
+}
+
+func (p *sProgram) watchLoop(w *sTargetWatcher) {
+	defer w.watcher.Close()
+	var (
+		timer   = time.NewTimer(recheckDelay)
+		pending <-chan time.Time
+		changed = make(map[string]bool)
+	)
+	timer.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-w.stop:
+			return
+		case ev, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			if ev.Has(fsnotify.Create) && w.covered(ev.Name) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					w.addTree(ev.Name)
+				}
+			}
+			changed[ev.Name] = true
+			timer.Reset(recheckDelay)
+			pending = timer.C
+		case <-pending:
+			pending = nil
+			names := changed
+			changed = make(map[string]bool)
+			p.recheck(names)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logx.Warnln(i18n.T(i18n.LogWatchFailed, "targets", err))
+		}
+	}
+}
+
+// recheck 重新检查变化的证书文件, 文件只归属第一个覆盖它的启用任务
+func (p *sProgram) recheck(names map[string]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var (
+		files   = p.changedFiles(names)
+		jobs    []*config.Job
+		results = make(map[string][]*checker.Response)
+		targets = make(map[string][]*config.Target)
+		invalid = make(map[string]*batch)
+		changes []*store.Change
+		now     = time.Now()
+	)
+	if len(files) == 0 {
+		return
+	}
+	logx.Infoln(i18n.T(i18n.LogRecheck, len(files)))
+	states, err := p.store.Certs()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+		states = map[string]*store.CertState{}
+	}
+	silences, err := p.store.Silences()
+	if err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateLoadFailed, err))
+	}
+	for _, file := range files {
+		job, t := p.owner(file)
+		if job == nil {
+			continue
+		}
+		if !slices.Contains(jobs, job) {
+			jobs = append(jobs, job)
+		}
+		res, err := p.newChecker(t).CheckCerts(p.ctx, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			b, ok := invalid[job.Name]
+			if !ok {
+				b = newBatch(job.Name, p.templates[job.Name])
+				invalid[job.Name] = b
+			}
+			if c := p.invalidCert(b, job, t, file, states[file], silences, err, now); c != nil {
+				changes = append(changes, c)
+			}
+			continue
+		}
+		delete(p.invalid, file)
+		for _, v := range res {
+			v.Job = job.Name
+			v.Owner = t.Owner
+			v.Team = t.Team
+			v.Labels = t.Labels
+			results[job.Name] = append(results[job.Name], v)
+			targets[job.Name] = append(targets[job.Name], t)
+		}
+	}
+	for _, job := range jobs {
+		if b, ok := invalid[job.Name]; ok {
+			p.flush(b, now)
+		}
+		if res := results[job.Name]; len(res) > 0 {
+			p.evaluate(job, res, targets[job.Name], true)
+			p.mergeLatest(job.Name, res)
+		}
+	}
+	if err = p.store.SaveChanges(changes...); err != nil {
+		logx.Warnln(i18n.T(i18n.LogStateSaveFailed, err))
+	}
+}
+
+// changedFiles 将变化的路径展开为需要检查的文件: 新建的目录检查其下所有文件,
+// 不属于任何目标的文件(如 Kubernetes 挂载中切换的 ..data 链接)检查同目录下的文件
+func (p *sProgram) changedFiles(names map[string]bool) []string {
+	var files = make(map[string]bool)
+	for name := range names {
+		info, err := os.Lstat(name)
+		if err != nil {
+			// 文件已被删除或改名, 新文件会有单独的事件
+			continue
+		}
+		owned, _ := p.owner(name)
+		switch {
+		case info.IsDir():
+			_ = filepath.WalkDir(name, func(path string, d fs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					files[path] = true
+				}
+				return nil
+			})
+		case owned != nil:
+			files[name] = true
+		default:
+			dir := filepath.Dir(name)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				if !e.IsDir() {
+					files[filepath.Join(dir, e.Name())] = true
+				}
+			}
+		}
+	}
+	var res = make([]string, 0, len(files))
+	for file := range files {
+		if j, _ := p.owner(file); j != nil {
+			res = append(res, file)
+		}
+	}
+	slices.Sort(res)
+	return res
+}
+
+// owner 返回覆盖文件的第一个启用任务及目标
+func (p *sProgram) owner(file string) (*config.Job, *config.Target) {
+	for _, j := range p.conf.EnabledJobs() {
+		if t := covers(j, file); t != nil {
+			return j, t
+		}
+	}
+	return nil, nil
+}
+
+// covers 返回任务中覆盖文件的第一个目标
+func covers(job *config.Job, file string) *config.Target {
+	for _, t := range job.Targets {
+		if strings.HasSuffix(file, t.Suffix) && within(filepath.Clean(t.Path), file) {
+			return t
+		}
+	}
+	return nil
+}
+
+// within 判断 path 是否为 root 本身或位于 root 之下
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// invalidCert 通知新部署的无效证书文件, 同一文件内容只通知一次, 返回变化记录
+func (p *sProgram) invalidCert(b *batch, job *config.Job, t *config.Target, path string, prev *store.CertState, silences []*store.Silence, cause error, now time.Time) *store.Change {
+	sum, err := fileSum(path)
+	if err != nil {
+		return nil
+	}
+	if last, ok := p.invalid[path]; ok && last == sum {
+		return nil
+	}
+	p.invalid[path] = sum
+	logx.Warnln(i18n.T(i18n.LogInvalidCert, path, cause))
+	res := &checker.Response{
+		Path:   path,
+		Job:    job.Name,
+		Owner:  t.Owner,
+		Team:   t.Team,
+		Labels: t.Labels,
+		Status: statusExpired,
+	}
+	if silenced(silences, res, now) {
+		logx.Infoln(i18n.T(i18n.LogSilenced, path, ""))
+	} else {
+		item := &reportItem{
+			Kind:     kindInvalid,
+			Path:     path,
+			Owner:    t.Owner,
+			Team:     t.Team,
+			Labels:   t.Labels,
+			Deployed: true,
+			Error:    cause.Error(),
+		}
+		tier := tierOf(job, statusExpired)
+		state := &store.CertState{Path: path, Status: statusExpired}
+		for _, name := range p.channels(job, res, tier, state, nil, eventAlert) {
+			p.deliver(b, name, item, true, now)
+		}
+	}
+	c := &store.Change{
+		Path:   path,
+		Status: statusInvalid,
+		Time:   now,
+	}
+	if prev != nil {
+		c.PrevStatus = prev.Status
+	}
+	return c
+}
+
+// mergeLatest 用重新检查的结果替换任务最近一次结果中的对应证书, 并更新指标
+func (p *sProgram) mergeLatest(job string, res []*checker.Response) {
+	p.rw.Lock()
+	defer p.rw.Unlock()
+	last, ok := p.latest[job]
+	if !ok || last.Error != "" {
+		return
+	}
+	var index = make(map[string]int, len(last.Results))
+	for i, v := range last.Results {
+		index[v.Path] = i
+	}
+	results := slices.Clone(last.Results)
+	for _, v := range res {
+		if i, ok := index[v.Path]; ok {
+			results[i] = v
+		} else {
+			results = append(results, v)
+		}
+	}
+	merged := *last
+	merged.Results = results
+	p.latest[job] = &merged
+	metrics.Set(job, results)
+}
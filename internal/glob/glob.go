@@ -0,0 +1,40 @@
+package glob
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Compile 将通配符转换为正则, * 与 ? 不跨越目录, ** 匹配任意层级
+func Compile(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			// 按字符而不是字节转义, 以支持非 ASCII 的路径
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// Match 判断 name 是否匹配通配符, 通配符非法时返回 false
+func Match(pattern, name string) bool {
+	re, err := Compile(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(name)
+}
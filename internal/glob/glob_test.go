@@ -0,0 +1,34 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"/etc/ssl/*.crt", "/etc/ssl/a.crt", true},
+		{"/etc/ssl/*.crt", "/etc/ssl/sub/a.crt", false},
+		{"/etc/ssl/**.crt", "/etc/ssl/sub/a.crt", true},
+		{"/etc/**/a.crt", "/etc/ssl/sub/a.crt", true},
+		{"/etc/ssl/?.crt", "/etc/ssl/a.crt", true},
+		{"/etc/ssl/?.crt", "/etc/ssl/ab.crt", false},
+		{"/etc/ssl/?.crt", "/etc/ssl//.crt", false},
+		// 非 ASCII 字符
+		{"/证书/*.crt", "/证书/a.crt", true},
+		{"/证书/*.crt", "/证书/生产/a.crt", false},
+		{"/证书/**", "/证书/生产/a.crt", true},
+		{"/certs/?.crt", "/certs/证.crt", true},
+		{"*.例子.com", "www.例子.com", true},
+		// 正则元字符按字面匹配
+		{"/etc/ssl/a.crt", "/etc/ssl/axcrt", false},
+		{"/etc/ssl/(a)+[b].crt", "/etc/ssl/(a)+[b].crt", true},
+		{"/etc/ssl/a$^|{1}.crt", "/etc/ssl/a$^|{1}.crt", true},
+		{`C:\certs\*.crt`, `C:\certs\a.crt`, true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
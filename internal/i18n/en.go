@@ -0,0 +1,101 @@
+package i18n
+
+var en = map[string]string{
+	LogHostnameFailed:     "failed to get hostname: %v",
+	LogLanIPFailed:        "failed to get internal ip: %v",
+	LogWanIPFailed:        "failed to get external ip: %v",
+	LogCheckStart:         "checking certificates (%s)...",
+	LogCheckFailed:        "failed to check certificates: %v",
+	LogCheckDone:          "certificate check finished (%s)...",
+	LogStateLoadFailed:    "failed to load state file: %v",
+	LogStateSaveFailed:    "failed to save state file: %v",
+	LogSilenced:           "certificate is silenced, skip notification: %s (%s)",
+	LogSendRetry:          "failed to send alert, retry in %s: %v",
+	LogSendFailed:         "failed to send alert to channel %s, saved to outbox: %v",
+	LogOutboxDropped:      "alert channel %s no longer exists, drop pending message",
+	LogDigestDropped:      "alert channel %s no longer exists, drop %d held notifications",
+	LogOutboxFlushed:      "delivered pending message of channel %s",
+	LogIncidentFailed:     "failed to update incident %[2]s on channel %[1]s: %[3]v",
+	LogValidateStart:      "running startup validation...",
+	LogValidateChecked:    "job %s checked, %d certificates found",
+	LogValidateSent:       "test alert delivered to channel %s",
+	LogValidateSendFailed: "failed to deliver test alert to channel %s: %v",
+	LogValidateFailed:     "startup validation failed with %d errors",
+	LogValidatePassed:     "startup validation passed",
+	LogReloading:          "reloading config on %s...",
+	LogReloaded:           "config reloaded, %d jobs enabled",
+	LogReloadFailed:       "failed to reload config, keep using the current one: %v",
+	LogReloadRestart:      "changes to the state file and listen addresses take effect after restart",
+	LogWatchFailed:        "failed to watch %s: %v",
+	LogWatchTargets:       "watching %d directories for certificate changes",
+	LogRecheck:            "certificate files changed, rechecking %d files",
+	LogInvalidCert:        "invalid certificate file %s: %v",
+
+	ErrLanIPNotFound:  "no internal IP address found",
+	ErrWanIPThreshold: "no IP found above threshold %.2f",
+
+	AlertTitle:            "Domain certificates expiring soon",
+	AlertHostname:         "Hostname",
+	AlertLanIP:            "LAN IP",
+	AlertWanIP:            "WAN IP",
+	AlertThresholdTitle:   "Domains reaching the alert threshold",
+	AlertExpiresIn:        "expires in <font color=FF0000> %d </font> days",
+	AlertThresholdHint:    "Please renew the certificates above in advance",
+	AlertExpiredTitle:     "Expired domains",
+	AlertExpiredHint:      "The domains above have expired, please verify and follow up",
+	AlertRenewedTitle:     "Renewed domains",
+	AlertRenewedItem:      "expiry %s → %s, serial %s → %s",
+	AlertDigestHint:       "Includes notifications held during quiet hours or maintenance windows",
+	AlertPart:             "(%d/%d)",
+	AlertTruncated:        "Message too long, %d more messages omitted",
+	AlertFullReport:       "Full report: %s",
+	AlertIncidentExpiring: "Certificate of %s expires in %d days (%s)",
+	AlertIncidentExpired:  "Certificate of %s expired %d days ago (%s)",
+	AlertIncidentResolved: "Certificate of %s renewed, new expiry %s",
+	AlertIncidentInvalid:  "Certificate file %s is invalid: %s",
+	AlertIncidentRemoved:  "Certificate at %s is no longer checked, the file was deleted or its target removed",
+	AlertDeployed:         "(newly deployed)",
+	AlertInvalidTitle:     "Invalid certificate files",
+	AlertInvalidHint:      "The files above are not valid certificates, please check the deployment",
+	AlertTestTitle:        "Certificate checker test alert",
+	AlertTestText:         "This is a test alert to confirm the channel works  \n- Hostname: %s  \n- LAN IP: %s  \n- WAN IP: %s  \n- %d certificates checked",
+
+	WebTitle:       "Certificate inventory",
+	WebLastRun:     "Last check",
+	WebNoData:      "No check has run yet",
+	WebSearch:      "Search domain, path or issuer",
+	WebLabelFilter: "Label key=value",
+	WebFilter:      "Filter",
+	WebReset:       "Reset",
+	WebAllStatus:   "All statuses",
+	WebTotal:       "%d certificates",
+	WebDomain:      "Domain",
+	WebPath:        "Path",
+	WebIssuer:      "Issuer",
+	WebSubject:     "Subject",
+	WebNotBefore:   "Not before",
+	WebNotAfter:    "Expires",
+	WebDays:        "Days left",
+	WebStatus:      "Status",
+	WebLabels:      "Labels",
+	WebOwner:       "Owner",
+	WebTeam:        "Team",
+	WebSilenced:    "Silenced",
+	WebSerial:      "Serial",
+	WebFingerprint: "SHA-256 fingerprint",
+	WebSANs:        "Subject alternative names",
+	WebKey:         "Public key",
+	WebSignature:   "Signature algorithm",
+	WebCA:          "CA certificate",
+	WebChain:       "Certificate chain",
+	WebChainFailed: "Failed to read the certificate chain: %v",
+	WebHistory:     "Status history",
+	WebNoHistory:   "No records",
+	WebTimeline:    "Deployment timeline",
+	WebFirstSeen:   "First seen",
+	WebLastSeen:    "Last seen",
+	WebJob:         "Job",
+	WebTime:        "Time",
+	WebNotFound:    "Certificate not found",
+	WebBack:        "Back to list",
+}
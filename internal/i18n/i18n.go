@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	EN = "en"
+	ZH = "zh"
+)
+
+// 默认语言, 保持与历史版本一致
+const Default = ZH
+
+var catalogs = map[string]map[string]string{
+	EN: en,
+	ZH: zh,
+}
+
+var logLang atomic.Value
+
+func init() {
+	logLang.Store(Default)
+}
+
+// Normalize 将 zh-CN, en_US 等写法归一为目录中的语言, 未知语言返回默认语言
+func Normalize(lang string) string {
+	lang = primary(lang)
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	return Default
+}
+
+// Supported 判断语言是否存在对应的消息目录
+func Supported(lang string) bool {
+	_, ok := catalogs[primary(lang)]
+	return ok
+}
+
+func primary(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "-_."); i > 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// SetLang 设置日志使用的语言
+func SetLang(lang string) {
+	logLang.Store(Normalize(lang))
+}
+
+// Lang 返回日志使用的语言
+func Lang() string {
+	return logLang.Load().(string)
+}
+
+// T 按日志语言翻译消息
+func T(key string, args ...any) string {
+	return Tl(Lang(), key, args...)
+}
+
+// Tl 按指定语言翻译消息, 缺失时回退到默认语言, 仍缺失则返回 key
+func Tl(lang, key string, args ...any) string {
+	format, ok := catalogs[Normalize(lang)][key]
+	if !ok {
+		format, ok = catalogs[Default][key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Translator 返回绑定到指定语言的翻译函数, 供模板使用
+func Translator(lang string) func(key string, args ...any) string {
+	lang = Normalize(lang)
+	return func(key string, args ...any) string {
+		return Tl(lang, key, args...)
+	}
+}
@@ -0,0 +1,111 @@
+package i18n
+
+// 日志消息
+const (
+	LogHostnameFailed     = "log.hostname_failed"
+	LogLanIPFailed        = "log.lan_ip_failed"
+	LogWanIPFailed        = "log.wan_ip_failed"
+	LogCheckStart         = "log.check_start"
+	LogCheckFailed        = "log.check_failed"
+	LogCheckDone          = "log.check_done"
+	LogStateLoadFailed    = "log.state_load_failed"
+	LogStateSaveFailed    = "log.state_save_failed"
+	LogSilenced           = "log.silenced"
+	LogSendRetry          = "log.send_retry"
+	LogSendFailed         = "log.send_failed"
+	LogOutboxDropped      = "log.outbox_dropped"
+	LogDigestDropped      = "log.digest_dropped"
+	LogOutboxFlushed      = "log.outbox_flushed"
+	LogIncidentFailed     = "log.incident_failed"
+	LogValidateStart      = "log.validate_start"
+	LogValidateChecked    = "log.validate_checked"
+	LogValidateSent       = "log.validate_sent"
+	LogValidateSendFailed = "log.validate_send_failed"
+	LogValidateFailed     = "log.validate_failed"
+	LogValidatePassed     = "log.validate_passed"
+	LogReloading          = "log.reloading"
+	LogReloaded           = "log.reloaded"
+	LogReloadFailed       = "log.reload_failed"
+	LogReloadRestart      = "log.reload_restart"
+	LogWatchFailed        = "log.watch_failed"
+	LogWatchTargets       = "log.watch_targets"
+	LogRecheck            = "log.recheck"
+	LogInvalidCert        = "log.invalid_cert"
+)
+
+// 错误消息
+const (
+	ErrLanIPNotFound  = "err.lan_ip_not_found"
+	ErrWanIPThreshold = "err.wan_ip_threshold"
+)
+
+// 告警消息
+const (
+	AlertTitle            = "alert.title"
+	AlertHostname         = "alert.hostname"
+	AlertLanIP            = "alert.lan_ip"
+	AlertWanIP            = "alert.wan_ip"
+	AlertThresholdTitle   = "alert.threshold_title"
+	AlertExpiresIn        = "alert.expires_in"
+	AlertThresholdHint    = "alert.threshold_hint"
+	AlertExpiredTitle     = "alert.expired_title"
+	AlertExpiredHint      = "alert.expired_hint"
+	AlertRenewedTitle     = "alert.renewed_title"
+	AlertRenewedItem      = "alert.renewed_item"
+	AlertDigestHint       = "alert.digest_hint"
+	AlertPart             = "alert.part"
+	AlertTruncated        = "alert.truncated"
+	AlertFullReport       = "alert.full_report"
+	AlertIncidentExpiring = "alert.incident_expiring"
+	AlertIncidentExpired  = "alert.incident_expired"
+	AlertIncidentResolved = "alert.incident_resolved"
+	AlertIncidentInvalid  = "alert.incident_invalid"
+	AlertIncidentRemoved  = "alert.incident_removed"
+	AlertDeployed         = "alert.deployed"
+	AlertInvalidTitle     = "alert.invalid_title"
+	AlertInvalidHint      = "alert.invalid_hint"
+	AlertTestTitle        = "alert.test_title"
+	AlertTestText         = "alert.test_text"
+)
+
+// 网页控制台
+const (
+	WebTitle       = "web.title"
+	WebLastRun     = "web.last_run"
+	WebNoData      = "web.no_data"
+	WebSearch      = "web.search"
+	WebLabelFilter = "web.label_filter"
+	WebFilter      = "web.filter"
+	WebReset       = "web.reset"
+	WebAllStatus   = "web.all_status"
+	WebTotal       = "web.total"
+	WebDomain      = "web.domain"
+	WebPath        = "web.path"
+	WebIssuer      = "web.issuer"
+	WebSubject     = "web.subject"
+	WebNotBefore   = "web.not_before"
+	WebNotAfter    = "web.not_after"
+	WebDays        = "web.days"
+	WebStatus      = "web.status"
+	WebLabels      = "web.labels"
+	WebOwner       = "web.owner"
+	WebTeam        = "web.team"
+	WebSilenced    = "web.silenced"
+	WebSerial      = "web.serial"
+	WebFingerprint = "web.fingerprint"
+	WebSANs        = "web.sans"
+	WebKey         = "web.key"
+	WebSignature   = "web.signature"
+	WebCA          = "web.ca"
+	WebChain       = "web.chain"
+	WebChainFailed = "web.chain_failed"
+	WebHistory     = "web.history"
+	WebNoHistory   = "web.no_history"
+	WebTimeline    = "web.timeline"
+	WebFirstSeen   = "web.first_seen"
+	WebLastSeen    = "web.last_seen"
+	WebJob         = "web.job"
+	WebTime        = "web.time"
+	WebNotFound    = "web.not_found"
+	WebBack        = "web.back"
+)
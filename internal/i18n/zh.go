@@ -0,0 +1,101 @@
+package i18n
+
+var zh = map[string]string{
+	LogHostnameFailed:     "获取主机名失败: %v",
+	LogLanIPFailed:        "获取内网ip失败: %v",
+	LogWanIPFailed:        "获取外网ip失败: %v",
+	LogCheckStart:         "开始检查证书 (%s)...",
+	LogCheckFailed:        "检查证书失败: %v",
+	LogCheckDone:          "证书检查完成 (%s)...",
+	LogStateLoadFailed:    "读取状态文件失败: %v",
+	LogStateSaveFailed:    "保存状态文件失败: %v",
+	LogSilenced:           "证书已静默, 跳过通知: %s (%s)",
+	LogSendRetry:          "发送告警失败, %s 后重试: %v",
+	LogSendFailed:         "发送告警到渠道 %s 失败, 已保存到待发送队列: %v",
+	LogOutboxDropped:      "告警渠道 %s 已不存在, 丢弃待发送的消息",
+	LogDigestDropped:      "告警渠道 %s 已不存在, 丢弃 %d 条暂存的通知",
+	LogOutboxFlushed:      "已补发渠道 %s 的待发送消息",
+	LogIncidentFailed:     "渠道 %s 更新事件 %s 失败: %v",
+	LogValidateStart:      "开始启动校验...",
+	LogValidateChecked:    "任务 %s 检查完成, 共 %d 个证书",
+	LogValidateSent:       "渠道 %s 测试消息发送成功",
+	LogValidateSendFailed: "渠道 %s 测试消息发送失败: %v",
+	LogValidateFailed:     "启动校验失败, %d 项未通过",
+	LogValidatePassed:     "启动校验通过",
+	LogReloading:          "收到 %s, 重新加载配置...",
+	LogReloaded:           "配置已重新加载, 共 %d 个启用的任务",
+	LogReloadFailed:       "重新加载配置失败, 继续使用当前配置: %v",
+	LogReloadRestart:      "状态文件与监听地址的修改需要重启后生效",
+	LogWatchFailed:        "监听文件 %s 失败: %v",
+	LogWatchTargets:       "监听 %d 个目录中的证书文件变化",
+	LogRecheck:            "证书文件发生变化, 重新检查 %d 个文件",
+	LogInvalidCert:        "证书文件 %s 无效: %v",
+
+	ErrLanIPNotFound:  "未找到内网 IP 地址",
+	ErrWanIPThreshold: "没有找到满足阈值 %.2f 的 IP",
+
+	AlertTitle:            "域名证书即将过期",
+	AlertHostname:         "主机名",
+	AlertLanIP:            "内网IP",
+	AlertWanIP:            "外网IP",
+	AlertThresholdTitle:   "触发告警阈值域名",
+	AlertExpiresIn:        "还有 <font color=FF0000> %d </font> 天过期",
+	AlertThresholdHint:    "上述域名请提前更换证书",
+	AlertExpiredTitle:     "失效域名",
+	AlertExpiredHint:      "上述域名已经过期，请确认并进行后续处理",
+	AlertRenewedTitle:     "已续期域名",
+	AlertRenewedItem:      "过期时间 %s → %s, 序列号 %s → %s",
+	AlertDigestHint:       "包含静默时段或维护窗口内暂存的通知",
+	AlertPart:             "(%d/%d)",
+	AlertTruncated:        "消息过长, 已省略后续 %d 条消息",
+	AlertFullReport:       "完整报告: %s",
+	AlertIncidentExpiring: "%s 的证书将在 %d 天后过期 (%s)",
+	AlertIncidentExpired:  "%s 的证书已过期 %d 天 (%s)",
+	AlertIncidentResolved: "%s 的证书已续期, 新的过期时间为 %s",
+	AlertIncidentInvalid:  "%s 的证书文件无效: %s",
+	AlertIncidentRemoved:  "%s 的证书已不再检查, 文件已删除或目标已移除",
+	AlertDeployed:         "(新部署)",
+	AlertInvalidTitle:     "无效的证书文件",
+	AlertInvalidHint:      "上述文件无法解析为有效的证书，请检查部署",
+	AlertTestTitle:        "证书检查测试消息",
+	AlertTestText:         "这是一条测试消息, 用于确认告警渠道可用  \n- 主机名: %s  \n- 内网IP: %s  \n- 外网IP: %s  \n- 检查到 %d 个证书",
+
+	WebTitle:       "证书清单",
+	WebLastRun:     "最近检查",
+	WebNoData:      "尚未执行检查",
+	WebSearch:      "搜索域名, 路径或颁发者",
+	WebLabelFilter: "标签 key=value",
+	WebFilter:      "筛选",
+	WebReset:       "重置",
+	WebAllStatus:   "全部状态",
+	WebTotal:       "共 %d 个证书",
+	WebDomain:      "域名",
+	WebPath:        "路径",
+	WebIssuer:      "颁发者",
+	WebSubject:     "主题",
+	WebNotBefore:   "生效时间",
+	WebNotAfter:    "过期时间",
+	WebDays:        "剩余天数",
+	WebStatus:      "状态",
+	WebLabels:      "标签",
+	WebOwner:       "负责人",
+	WebTeam:        "团队",
+	WebSilenced:    "已静默",
+	WebSerial:      "序列号",
+	WebFingerprint: "SHA-256 指纹",
+	WebSANs:        "备用名称",
+	WebKey:         "公钥算法",
+	WebSignature:   "签名算法",
+	WebCA:          "CA 证书",
+	WebChain:       "证书链",
+	WebChainFailed: "读取证书链失败: %v",
+	WebHistory:     "状态变化",
+	WebNoHistory:   "暂无记录",
+	WebTimeline:    "部署记录",
+	WebFirstSeen:   "首次检查到",
+	WebLastSeen:    "最近检查到",
+	WebJob:         "任务",
+	WebTime:        "时间",
+	WebNotFound:    "证书不存在",
+	WebBack:        "返回列表",
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/busybox-org/cert-checker/internal/core/checker"
+)
+
+var (
+	invalidLabel = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	escaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+var (
+	mu sync.RWMutex
+	// 各任务最近一次检查的结果与时间, key 为任务名称
+	results = map[string][]*checker.Response{}
+	lastRun = map[string]time.Time{}
+)
+
+// Set 使用任务最近一次检查的结果替换该任务的指标
+func Set(job string, res []*checker.Response) {
+	mu.Lock()
+	defer mu.Unlock()
+	results[job] = res
+	lastRun[job] = time.Now()
+}
+
+// Remove 移除已不存在的任务的指标
+func Remove(job string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(results, job)
+	delete(lastRun, job)
+}
+
+// Handler 以 Prometheus 文本格式输出指标
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+// Write 以 Prometheus 文本格式写出指标
+func Write(w io.Writer) {
+	mu.RLock()
+	defer mu.RUnlock()
+	var jobs []string
+	for job := range lastRun {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	_, _ = fmt.Fprintln(w, "# HELP cert_checker_expiry_days Remaining days until the certificate expires.")
+	_, _ = fmt.Fprintln(w, "# TYPE cert_checker_expiry_days gauge")
+	for _, job := range jobs {
+		for _, v := range results[job] {
+			_, _ = fmt.Fprintf(w, "cert_checker_expiry_days{%s} %d\n", labels(v), v.ExpiredDays)
+		}
+	}
+	_, _ = fmt.Fprintln(w, "# HELP cert_checker_not_after_timestamp_seconds Expiry time of the certificate.")
+	_, _ = fmt.Fprintln(w, "# TYPE cert_checker_not_after_timestamp_seconds gauge")
+	for _, job := range jobs {
+		for _, v := range results[job] {
+			_, _ = fmt.Fprintf(w, "cert_checker_not_after_timestamp_seconds{%s} %d\n", labels(v), v.NotAfter.Unix())
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "# HELP cert_checker_last_run_timestamp_seconds Time of the last check run.")
+	_, _ = fmt.Fprintln(w, "# TYPE cert_checker_last_run_timestamp_seconds gauge")
+	for _, job := range jobs {
+		_, _ = fmt.Fprintf(w, "cert_checker_last_run_timestamp_seconds{job=\"%s\"} %d\n", escaper.Replace(job), lastRun[job].Unix())
+	}
+}
+
+func labels(v *checker.Response) string {
+	var pairs = [][2]string{
+		{"job", v.Job},
+		{"path", v.Path},
+		{"domain", v.DomainName},
+		{"status", v.Status},
+		{"owner", v.Owner},
+		{"team", v.Team},
+		{"silenced", strconv.FormatBool(v.Silenced)},
+	}
+	var keys []string
+	for k := range v.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		pairs = append(pairs, [2]string{"label_" + invalidLabel.ReplaceAllString(k, "_"), v.Labels[k]})
+	}
+	var b strings.Builder
+	for i, pair := range pairs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		_, _ = fmt.Fprintf(&b, `%s="%s"`, pair[0], escaper.Replace(pair[1]))
+	}
+	return b.String()
+}
@@ -2,13 +2,14 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/xmapst/logx"
 
+	"github.com/busybox-org/cert-checker/internal/i18n"
 	"github.com/busybox-org/cert-checker/internal/resolvers/base"
 	"github.com/busybox-org/cert-checker/internal/resolvers/targets"
 )
@@ -37,7 +38,7 @@ func GetInternalIP() (string, error) {
 			}
 		}
 	}
-	return "", fmt.Errorf("未找到内网 IP 地址")
+	return "", errors.New(i18n.T(i18n.ErrLanIPNotFound))
 }
 
 func GetExternalIP() (string, error) {
@@ -110,5 +111,5 @@ func optimizedRetrieve(timeout time.Duration, threshold float64) (*base.ScoredIP
 		}
 	}
 
-	return nil, fmt.Errorf("没有找到满足阈值 %.2f 的 IP", threshold)
+	return nil, errors.New(i18n.T(i18n.ErrWanIPThreshold, threshold))
 }
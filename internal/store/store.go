@@ -0,0 +1,660 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/busybox-org/cert-checker/internal/config"
+	"github.com/busybox-org/cert-checker/internal/osext"
+)
+
+const (
+	bucketCerts     = "certs"
+	bucketSilences  = "silences"
+	bucketDigests   = "digests"
+	bucketOutbox    = "outbox"
+	bucketIncidents = "incidents"
+	bucketChanges   = "changes"
+	bucketParsed    = "parsed"
+	bucketHistory   = "history"
+)
+
+type IStore interface {
+	// Certs 返回所有证书的历史状态, key 为证书路径
+	Certs() (map[string]*CertState, error)
+	// SaveCerts 保存证书状态
+	SaveCerts(states ...*CertState) error
+	// Silences 返回所有静默规则, 包括已过期的
+	Silences() ([]*Silence, error)
+	// SaveSilence 新增或更新静默规则
+	SaveSilence(silence *Silence) error
+	// QueueDigests 暂存静默时段内的通知
+	QueueDigests(digests ...*Digest) error
+	// TakeDigests 取出并删除渠道暂存的通知, 按入队顺序返回
+	TakeDigests(channel string) ([]*Digest, error)
+	// DigestChannels 返回有暂存通知的渠道
+	DigestChannels() ([]string, error)
+	// Outbox 返回所有发送失败待补发的消息, 按入队顺序返回
+	Outbox() ([]*Message, error)
+	// SaveMessage 新增或更新待补发的消息, ID 为空时分配新的 ID
+	SaveMessage(msg *Message) error
+	// DeleteMessage 删除已补发的消息
+	DeleteMessage(id string) error
+	// Incidents 返回所有处于打开状态的事件
+	Incidents() ([]*Incident, error)
+	// SaveIncident 记录打开的事件
+	SaveIncident(incident *Incident) error
+	// DeleteIncident 删除已恢复的事件
+	DeleteIncident(channel, path string) error
+	// Changes 返回证书的状态变化记录, 按时间顺序返回
+	Changes(path string) ([]*Change, error)
+	// SaveChanges 追加状态变化记录
+	SaveChanges(changes ...*Change) error
+	// Observe 记录本次检查到的证书, 路径上的证书未变化且距最近一次观测不超过 gap 时只延长其时间,
+	// 否则开始新的记录, 以保留证书被移除或未检查到的时段
+	Observe(gap time.Duration, observations ...*Observation) error
+	// History 返回路径上先后部署过的证书, 按首次检查到的时间顺序返回
+	History(path string) ([]*Observation, error)
+	// DomainHistory 返回域名在各路径上先后部署过的证书, 按路径及时间顺序返回
+	DomainHistory(domain string) ([]*Observation, error)
+	// ParseCache 返回证书文件的解析结果缓存, 值由调用方序列化
+	ParseCache() (map[string]json.RawMessage, error)
+	// SaveParseCache 新增或更新解析结果缓存, 并删除 removed 中的条目
+	SaveParseCache(entries map[string]json.RawMessage, removed []string) error
+}
+
+// CertState 证书在上一次检查时的状态
+type CertState struct {
+	Path         string    `json:"path"`
+	DomainName   string    `json:"domain_name"`
+	Status       string    `json:"status"`
+	ExpiredDays  int       `json:"expired_days"`
+	NotAfter     time.Time `json:"not_after"`
+	Serial       string    `json:"serial"`
+	Fingerprint  string    `json:"fingerprint"`
+	LastNotified time.Time `json:"last_notified"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Silence 静默规则, 指纹, 域名与路径中已设置的条件需要全部满足
+type Silence struct {
+	ID          string `json:"id"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// 域名通配符
+	Domain string `json:"domain,omitempty"`
+	// 路径通配符
+	Path      string    `json:"path,omitempty"`
+	Comment   string    `json:"comment"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Active 判断静默规则在指定时间是否生效
+func (s *Silence) Active(now time.Time) bool {
+	return now.Before(s.ExpiresAt)
+}
+
+// Digest 静默时段内暂存的通知, Item 由调用方序列化
+type Digest struct {
+	Channel string `json:"channel"`
+	// 产生通知的任务, 合并摘要时使用该任务的报告模板
+	Job      string          `json:"job,omitempty"`
+	Item     json.RawMessage `json:"item"`
+	QueuedAt time.Time       `json:"queued_at"`
+}
+
+// Message 发送失败待补发的消息
+type Message struct {
+	ID        string    `json:"id"`
+	Channel   string    `json:"channel"`
+	Title     string    `json:"title,omitempty"`
+	Text      string    `json:"text"`
+	Severity  string    `json:"severity,omitempty"`
+	AtMobiles []string  `json:"at_mobiles,omitempty"`
+	AtUserIds []string  `json:"at_user_ids,omitempty"`
+	AtAll     bool      `json:"at_all,omitempty"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Incident 渠道上处于打开状态的事件, 证书恢复后需要关闭
+type Incident struct {
+	Channel    string    `json:"channel"`
+	Key        string    `json:"key"`
+	Path       string    `json:"path"`
+	DomainName string    `json:"domain_name"`
+	OpenedAt   time.Time `json:"opened_at"`
+	// 最近一次推送的事件, 由调用方序列化, 用于重新推送或恢复
+	Event json.RawMessage `json:"event,omitempty"`
+	// 最近一次成功推送的时间, 用于需要周期性重新推送的渠道
+	PushedAt time.Time `json:"pushed_at,omitempty"`
+	// 创建事件失败, 等待每分钟的任务重新推送
+	Pending bool `json:"pending,omitempty"`
+	// 恢复事件失败, 等待每分钟的任务重新恢复
+	Resolving bool `json:"resolving,omitempty"`
+}
+
+// Change 证书首次发现, 状态变化或被替换时的记录
+type Change struct {
+	Path        string    `json:"path"`
+	DomainName  string    `json:"domain_name"`
+	PrevStatus  string    `json:"prev_status,omitempty"`
+	Status      string    `json:"status"`
+	ExpiredDays int       `json:"expired_days"`
+	NotAfter    time.Time `json:"not_after"`
+	Serial      string    `json:"serial"`
+	Fingerprint string    `json:"fingerprint"`
+	Time        time.Time `json:"time"`
+}
+
+// Observation 在同一路径上连续检查到的同一证书, 证书被替换后追加新的记录
+type Observation struct {
+	Path        string    `json:"path"`
+	DomainName  string    `json:"domain_name"`
+	Fingerprint string    `json:"fingerprint"`
+	Serial      string    `json:"serial"`
+	Issuer      string    `json:"issuer"`
+	NotBefore   time.Time `json:"not_before"`
+	NotAfter    time.Time `json:"not_after"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+type sStore struct {
+	path string
+}
+
+// Path 相对路径以可执行文件所在目录为基准, 与自更新保持一致
+func Path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	dir, err := osext.ExecutableFolder()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// Open 按命令行参数与配置文件中的 state_file 打开状态存储, 供命令行子命令使用
+func Open(flags *pflag.FlagSet) (IStore, error) {
+	conf, err := config.Parse(flags)
+	if err != nil {
+		return nil, err
+	}
+	return New(Path(conf.StateFile)), nil
+}
+
+// New 返回基于 bbolt 的状态存储, 每次操作时打开文件并在结束后关闭,
+// 以便守护进程与命令行子命令可以同时访问
+func New(path string) IStore {
+	return &sStore{
+		path: path,
+	}
+}
+
+func (s *sStore) Certs() (map[string]*CertState, error) {
+	var res = make(map[string]*CertState)
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketCerts, func(key []byte, value []byte) error {
+			var state CertState
+			if err := json.Unmarshal(value, &state); err != nil {
+				return err
+			}
+			res[string(key)] = &state
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveCerts(states ...*CertState) error {
+	if len(states) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		for _, state := range states {
+			if err := put(tx, bucketCerts, state.Path, state); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sStore) Silences() ([]*Silence, error) {
+	var res []*Silence
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketSilences, func(key []byte, value []byte) error {
+			var silence Silence
+			if err := json.Unmarshal(value, &silence); err != nil {
+				return err
+			}
+			res = append(res, &silence)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveSilence(silence *Silence) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return put(tx, bucketSilences, silence.ID, silence)
+	})
+}
+
+func (s *sStore) QueueDigests(digests ...*Digest) error {
+	if len(digests) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketDigests))
+		if err != nil {
+			return err
+		}
+		for _, d := range digests {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err = put(tx, bucketDigests, fmt.Sprintf("%s/%020d", d.Channel, seq), d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sStore) TakeDigests(channel string) ([]*Digest, error) {
+	var (
+		res    []*Digest
+		exists bool
+		prefix = []byte(channel + "/")
+	)
+	// 先以只读方式确认是否有暂存, 避免每次都获取写锁
+	err := s.view(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(bucketDigests)); b != nil {
+			k, _ := b.Cursor().Seek(prefix)
+			exists = k != nil && bytes.HasPrefix(k, prefix)
+		}
+		return nil
+	})
+	if err != nil || !exists {
+		return nil, err
+	}
+	err = s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketDigests))
+		if b == nil {
+			return nil
+		}
+		var keys [][]byte
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var d Digest
+			if err := json.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			res = append(res, &d)
+			keys = append(keys, append([]byte(nil), k...))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) DigestChannels() ([]string, error) {
+	var res []string
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketDigests, func(key []byte, value []byte) error {
+			// 键为 渠道/序号, 渠道名称中可能包含 /
+			channel := string(key[:bytes.LastIndexByte(key, '/')])
+			if len(res) == 0 || res[len(res)-1] != channel {
+				res = append(res, channel)
+			}
+			return nil
+		})
+	})
+	return res, err
+}
+
+func (s *sStore) Outbox() ([]*Message, error) {
+	var res []*Message
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketOutbox, func(key []byte, value []byte) error {
+			var msg Message
+			if err := json.Unmarshal(value, &msg); err != nil {
+				return err
+			}
+			res = append(res, &msg)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveMessage(msg *Message) error {
+	return s.update(func(tx *bolt.Tx) error {
+		if msg.ID == "" {
+			b, err := tx.CreateBucketIfNotExists([]byte(bucketOutbox))
+			if err != nil {
+				return err
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			msg.ID = fmt.Sprintf("%020d", seq)
+		}
+		return put(tx, bucketOutbox, msg.ID, msg)
+	})
+}
+
+func (s *sStore) DeleteMessage(id string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketOutbox))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(id))
+	})
+}
+
+func (s *sStore) Incidents() ([]*Incident, error) {
+	var res []*Incident
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketIncidents, func(key []byte, value []byte) error {
+			var incident Incident
+			if err := json.Unmarshal(value, &incident); err != nil {
+				return err
+			}
+			res = append(res, &incident)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveIncident(incident *Incident) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return put(tx, bucketIncidents, incident.Channel+"/"+incident.Path, incident)
+	})
+}
+
+func (s *sStore) DeleteIncident(channel, path string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketIncidents))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(channel + "/" + path))
+	})
+}
+
+func (s *sStore) Changes(path string) ([]*Change, error) {
+	var (
+		res    []*Change
+		prefix = []byte(path + "\x00")
+	)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketChanges))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var change Change
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			res = append(res, &change)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveChanges(changes ...*Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketChanges))
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			// 路径与序号之间以 \x00 分隔, 避免按前缀查找时匹配到其他路径
+			if err = put(tx, bucketChanges, fmt.Sprintf("%s\x00%020d", change.Path, seq), change); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sStore) Observe(gap time.Duration, observations ...*Observation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketHistory))
+		if err != nil {
+			return err
+		}
+		for _, o := range observations {
+			key, last, err := lastObservation(b, o.Path)
+			if err != nil {
+				return err
+			}
+			if last != nil && last.Fingerprint == o.Fingerprint && o.FirstSeen.Sub(last.LastSeen) <= gap {
+				last.LastSeen = o.LastSeen
+				if err = put(tx, bucketHistory, string(key), last); err != nil {
+					return err
+				}
+				continue
+			}
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err = put(tx, bucketHistory, fmt.Sprintf("%s\x00%020d", o.Path, seq), o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lastObservation 返回路径上最近的一条记录, 键的格式与变化记录相同
+func lastObservation(b *bolt.Bucket, path string) ([]byte, *Observation, error) {
+	c := b.Cursor()
+	// 路径之后的第一个键的前一个键即为该路径的最后一条记录
+	k, v := c.Seek([]byte(path + "\x01"))
+	if k == nil {
+		k, v = c.Last()
+	} else {
+		k, v = c.Prev()
+	}
+	if k == nil || !bytes.HasPrefix(k, []byte(path+"\x00")) {
+		return nil, nil, nil
+	}
+	var o Observation
+	if err := json.Unmarshal(v, &o); err != nil {
+		return nil, nil, err
+	}
+	return bytes.Clone(k), &o, nil
+}
+
+func (s *sStore) History(path string) ([]*Observation, error) {
+	var (
+		res    []*Observation
+		prefix = []byte(path + "\x00")
+	)
+	err := s.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketHistory))
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var o Observation
+			if err := json.Unmarshal(v, &o); err != nil {
+				return err
+			}
+			res = append(res, &o)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) DomainHistory(domain string) ([]*Observation, error) {
+	var res []*Observation
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketHistory, func(key []byte, value []byte) error {
+			var o Observation
+			if err := json.Unmarshal(value, &o); err != nil {
+				return err
+			}
+			if o.DomainName == domain {
+				res = append(res, &o)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) ParseCache() (map[string]json.RawMessage, error) {
+	var res = make(map[string]json.RawMessage)
+	err := s.view(func(tx *bolt.Tx) error {
+		return forEach(tx, bucketParsed, func(key []byte, value []byte) error {
+			res[string(key)] = bytes.Clone(value)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *sStore) SaveParseCache(entries map[string]json.RawMessage, removed []string) error {
+	if len(entries) == 0 && len(removed) == 0 {
+		return nil
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucketParsed))
+		if err != nil {
+			return err
+		}
+		for key, value := range entries {
+			if err = b.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		for _, key := range removed {
+			if err = b.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *sStore) open(readonly bool) (*bolt.DB, error) {
+	if !readonly {
+		if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+			return nil, err
+		}
+	}
+	return bolt.Open(s.path, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: readonly,
+	})
+}
+
+func (s *sStore) view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		// 尚未写入过任何状态
+		return nil
+	}
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (s *sStore) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+func put(tx *bolt.Tx, bucket, key string, value any) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+func forEach(tx *bolt.Tx, bucket string, fn func(key []byte, value []byte) error) error {
+	b := tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(fn)
+}